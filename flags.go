@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/urfave/cli/v3"
+)
+
+// sourceFlags select the hosts and manifests to check
+func sourceFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "config",
+			Aliases:  []string{"C"},
+			Value:    "",
+			Usage:    "config file",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "domains",
+			Aliases:  []string{"d"},
+			Value:    "",
+			Usage:    "comma-separated list of domains to check (e.g., example.com,google.com:443)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "domains-file",
+			Aliases:  []string{"f"},
+			Value:    "",
+			Usage:    "file containing newline-separated domains to check",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "skip",
+			Value:    0,
+			Usage:    "number of lines to skip from --domains-file before parsing",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "limit",
+			Value:    0,
+			Usage:    "maximum number of lines to parse from --domains-file after --skip (0 means no limit)",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "manifests",
+			Usage:    "Kubernetes manifest file or directory whose kubernetes.io/tls Secrets are checked offline, repeatable",
+			Required: false,
+		},
+	}
+}
+
+// inspectFlags configure how local certificate files are read
+func inspectFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "inspect-key",
+			Usage:    "private key file to match against the inspected certificates, repeatable",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "keystore-password",
+			Value:    "",
+			Usage:    "password for inspected PKCS#12 bundles and Java keystores",
+			Sources:  cli.EnvVars("SSL_CERTS_CHECKER_KEYSTORE_PASSWORD"),
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "keystore-password-file",
+			Value:    "",
+			Usage:    "file containing the password for inspected keystores",
+			Required: false,
+		},
+	}
+}
+
+// connectionFlags configure how hosts are reached
+func connectionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "proxy",
+			Value:    "",
+			Usage:    "HTTP CONNECT or SOCKS5 proxy URL (default: HTTPS_PROXY/ALL_PROXY, honoring NO_PROXY)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "starttls",
+			Value:    "",
+			Usage:    "protocol to negotiate before TLS for hosts without a scheme (smtp, imap, pop3, ftp, xmpp, ...)",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "all-ips",
+			Value:    false,
+			Usage:    "check every resolved IPv4/IPv6 address of each host",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "scan-protocols",
+			Value:    false,
+			Usage:    "probe every TLS version and cipher suite each server accepts (one handshake per combination)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "client-cert",
+			Value:    "",
+			Usage:    "PEM client certificate presented when a server requests one (requires --client-key)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "client-key",
+			Value:    "",
+			Usage:    "PEM private key for --client-cert",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "client-p12",
+			Value:    "",
+			Usage:    "PKCS#12 bundle with the client certificate and key, instead of --client-cert/--client-key",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "client-p12-password",
+			Value:    "",
+			Usage:    "password for --client-p12",
+			Sources:  cli.EnvVars("SSL_CERTS_CHECKER_P12_PASSWORD"),
+			Required: false,
+		},
+	}
+}
+
+// commonFlags configure how certificates are evaluated, whatever their source
+func commonFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:     "timeout",
+			Aliases:  []string{"t"},
+			Value:    defaultDialerTimeout,
+			Usage:    "dialer timeout in second(s)",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "ocsp",
+			Value:    false,
+			Usage:    "query the OCSP responder listed in each certificate for its revocation status",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "crl",
+			Value:    false,
+			Usage:    "download the CRLs listed in each certificate and check its revocation status",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "crl-file",
+			Usage:    "local CRL file (DER or PEM) to check certificates against, repeatable for offline use",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "crl-cache-dir",
+			Value:    "",
+			Usage:    "directory caching downloaded CRLs (default: user cache directory)",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "warn-days",
+			Value:    defaultWarnDays,
+			Usage:    "report WARNING when a certificate expires within this many days (0 disables)",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "crit-days",
+			Value:    defaultCritDays,
+			Usage:    "report CRITICAL when a certificate expires within this many days (0 disables)",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "insecure",
+			Aliases:  []string{"k"},
+			Value:    false,
+			Usage:    "skip the verification of certificates",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "ca-file",
+			Usage:    "PEM bundle of additional trusted root certificates, repeatable",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "ca-dir",
+			Value:    "",
+			Usage:    "directory of PEM files with additional trusted root certificates",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "no-system-roots",
+			Value:    false,
+			Usage:    "do not trust the system root certificates, only --ca-file/--ca-dir",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "disable-rule",
+			Usage:    "weak cryptography rule to skip, repeatable (weak_rsa_key, weak_signature, deprecated_curve, long_validity, missing_server_auth, insufficient_scts)",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "ct",
+			Value:    false,
+			Usage:    "check that each leaf carries enough SCTs for the browser Certificate Transparency policy",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "ct-log-list",
+			Value:    "",
+			Usage:    "CT log list JSON used to verify SCT signatures (implies --ct)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "policy",
+			Value:    "",
+			Usage:    "YAML policy file with custom certificate rules",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "show-chain",
+			Value:    false,
+			Usage:    "include the full presented certificate chain in the output",
+			Required: false,
+		},
+	}
+}
+
+// outputFlags select the output format and destination
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Value:    "table",
+			Usage:    "output format (table, json, yaml)",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "fields",
+			Usage:    "comma-separated table columns to show, e.g. host,sha256_fingerprint,key_size (default: standard columns)",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "output-file",
+			Value:    "",
+			Usage:    "write formatted output to file (optional)",
+			Required: false,
+		},
+	}
+}
+
+// serveFlags configure the HTTP server of the serve command
+func serveFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "listen",
+			Value:    defaultListenAddress,
+			Usage:    "address the HTTP server listens on",
+			Required: false,
+		},
+		&cli.DurationFlag{
+			Name:     "interval",
+			Value:    defaultServeInterval,
+			Usage:    "time between two checks",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Value:    "json",
+			Usage:    "format of the served result (json, yaml)",
+			Required: false,
+		},
+	}
+}
+
+// flagSet joins flag groups into the flags of a command
+func flagSet(groups ...[]cli.Flag) []cli.Flag {
+	var flags []cli.Flag
+	for _, group := range groups {
+		flags = append(flags, group...)
+	}
+
+	return flags
+}
+
+// localFlags keeps the flags of the root command from being inherited by the
+// subcommands, which declare the flags they accept themselves
+func localFlags(flags []cli.Flag) []cli.Flag {
+	for _, flag := range flags {
+		switch f := flag.(type) {
+		case *cli.StringFlag:
+			f.Local = true
+		case *cli.StringSliceFlag:
+			f.Local = true
+		case *cli.IntFlag:
+			f.Local = true
+		case *cli.BoolFlag:
+			f.Local = true
+		case *cli.DurationFlag:
+			f.Local = true
+		}
+	}
+
+	return flags
+}
+
+// declares reports whether the command itself declares the named flag
+func declares(c *cli.Command, name string) bool {
+	for _, flag := range c.Flags {
+		if slices.Contains(flag.Names(), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rejectRootFlags fails when a flag of the root command is given to a subcommand.
+// The CLI library accepts the flags of every parent, but subcommands only read
+// their own, so a root flag placed before the subcommand name would be dropped.
+func rejectRootFlags(ctx context.Context, c *cli.Command) (context.Context, error) {
+	for _, flag := range c.Root().Flags {
+		name := flag.Names()[0]
+		if !flag.IsSet() || fromEnv(flag) {
+			continue
+		}
+
+		if declares(c, name) {
+			return ctx, cli.Exit(fmt.Sprintf("Error: flag --%s must be given after the %s command", name, c.FullName()), 1)
+		}
+
+		return ctx, cli.Exit(fmt.Sprintf("Error: flag --%s is not supported by the %s command", name, c.FullName()), 1)
+	}
+
+	return ctx, nil
+}
+
+// fromEnv reports whether a flag takes its value from an environment variable
+// rather than from the command line
+func fromEnv(flag cli.Flag) bool {
+	if f, ok := flag.(*cli.StringFlag); ok {
+		value, found := f.Sources.Lookup()
+		return found && f.Get() == value
+	}
+
+	return false
+}
+
+// commandFlags reads the flags declared by a command. Names the command does not
+// declare read as zero values rather than resolving to a parent command's flag.
+type commandFlags struct {
+	cmd *cli.Command
+}
+
+func (f commandFlags) String(name string) string {
+	if !declares(f.cmd, name) {
+		return ""
+	}
+	return f.cmd.String(name)
+}
+
+func (f commandFlags) StringSlice(name string) []string {
+	if !declares(f.cmd, name) {
+		return nil
+	}
+	return f.cmd.StringSlice(name)
+}
+
+func (f commandFlags) Int(name string) int {
+	if !declares(f.cmd, name) {
+		return 0
+	}
+	return f.cmd.Int(name)
+}
+
+func (f commandFlags) Bool(name string) bool {
+	if !declares(f.cmd, name) {
+		return false
+	}
+	return f.cmd.Bool(name)
+}
@@ -8,6 +8,8 @@ require (
 	github.com/jedib0t/go-pretty/v6 v6.6.8
 	github.com/urfave/cli/v3 v3.3.8
 	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/crypto v0.41.0
+	software.sslmate.com/src/go-pkcs12 v0.7.3
 )
 
 require (
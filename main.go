@@ -6,114 +6,235 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/guessi/ssl-certs-checker/pkg/app"
 	"github.com/guessi/ssl-certs-checker/pkg/config"
 	"github.com/urfave/cli/v3"
 )
 
-const defaultDialerTimeout = 5
+const (
+	defaultDialerTimeout = 5
+	defaultWarnDays      = 30
+	defaultCritDays      = 7
+
+	defaultListenAddress = ":8080"
+	defaultServeInterval = time.Hour
+
+	// exitCodeChanged is returned by diff when certificates changed, after the status exit codes
+	exitCodeChanged = 7
+)
 
 func main() {
-	cliApp := &cli.Command{
+	if err := newCommand().Run(context.Background(), os.Args); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newCommand builds the root command and its subcommands
+func newCommand() *cli.Command {
+	return &cli.Command{
 		Usage: "check SSL certificates at once",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:     "config",
-				Aliases:  []string{"C"},
-				Value:    "",
-				Usage:    "config file",
-				Required: false,
-			},
-			&cli.StringFlag{
-				Name:     "domains",
-				Aliases:  []string{"d"},
-				Value:    "",
-				Usage:    "comma-separated list of domains to check (e.g., example.com,google.com:443)",
-				Required: false,
-			},
-			&cli.StringFlag{
-				Name:     "domains-file",
-				Aliases:  []string{"f"},
-				Value:    "",
-				Usage:    "file containing newline-separated domains to check",
-				Required: false,
-			},
-			&cli.IntFlag{
-				Name:     "skip",
-				Value:    0,
-				Usage:    "number of lines to skip from --domains-file before parsing",
-				Required: false,
-			},
-			&cli.IntFlag{
-				Name:     "limit",
-				Value:    0,
-				Usage:    "maximum number of lines to parse from --domains-file after --skip (0 means no limit)",
+		// The root command keeps the flags of the single-command CLI for backward compatibility
+		Flags: localFlags(flagSet(
+			sourceFlags(),
+			[]cli.Flag{&cli.StringSliceFlag{
+				Name:     "inspect",
+				Usage:    "local certificate file or directory (PEM, DER, PKCS#7, PKCS#12) to inspect instead of connecting, repeatable",
 				Required: false,
+			}},
+			inspectFlags(),
+			connectionFlags(),
+			commonFlags(),
+			outputFlags(),
+		)),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			return runCheck(ctx, newAppConfig(c))
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "check",
+				Usage:  "check the certificates of live hosts and Kubernetes manifests",
+				Flags:  flagSet(sourceFlags(), connectionFlags(), commonFlags(), outputFlags()),
+				Before: rejectRootFlags,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					return runCheck(ctx, newAppConfig(c))
+				},
 			},
-			&cli.IntFlag{
-				Name:     "timeout",
-				Aliases:  []string{"t"},
-				Value:    defaultDialerTimeout,
-				Usage:    "dialer timeout in second(s)",
-				Required: false,
+			{
+				Name:      "inspect",
+				Usage:     "inspect local certificate files and directories without connecting",
+				ArgsUsage: "PATH...",
+				Flags:     flagSet(inspectFlags(), commonFlags(), outputFlags()),
+				Before:    rejectRootFlags,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.NArg() == 0 {
+						return cli.Exit("Error: inspect requires at least one file or directory", 1)
+					}
+
+					cfg := newAppConfig(c)
+					cfg.InspectPaths = c.Args().Slice()
+					return runCheck(ctx, cfg)
+				},
 			},
-			&cli.BoolFlag{
-				Name:     "insecure",
-				Aliases:  []string{"k"},
-				Value:    false,
-				Usage:    "skip the verification of certificates",
-				Required: false,
+			{
+				Name:   "serve",
+				Usage:  "check periodically and serve the latest result over HTTP",
+				Flags:  flagSet(sourceFlags(), connectionFlags(), commonFlags(), serveFlags()),
+				Before: rejectRootFlags,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					ctx, cancel := signalContext(ctx)
+					defer cancel()
+
+					opts := app.ServeOptions{
+						Listen:   c.String("listen"),
+						Interval: c.Duration("interval"),
+					}
+					if err := app.New().Serve(ctx, newAppConfig(c), opts); err != nil {
+						return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+					}
+
+					return nil
+				},
 			},
-			&cli.StringFlag{
-				Name:     "output",
-				Aliases:  []string{"o"},
-				Value:    "table",
-				Usage:    "output format (table, json, yaml)",
-				Required: false,
+			{
+				Name:      "diff",
+				Usage:     "compare a saved JSON or YAML result with another one or with a new check",
+				ArgsUsage: "BASELINE [CURRENT]",
+				Flags:     flagSet(sourceFlags(), connectionFlags(), commonFlags(), outputFlags()),
+				Before:    rejectRootFlags,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.NArg() < 1 || c.NArg() > 2 {
+						return cli.Exit("Error: diff requires a baseline result file and at most one current result file", 1)
+					}
+
+					cfg := newAppConfig(c)
+					current := c.Args().Get(1)
+					if current != "" && (cfg.HasHostSource() || len(cfg.ManifestPaths) > 0) {
+						return cli.Exit("Error: a current result file cannot be combined with --config, --domains, --domains-file, or --manifests", 1)
+					}
+
+					ctx, cancel := signalContext(ctx)
+					defer cancel()
+
+					diff, err := app.New().Diff(ctx, cfg, c.Args().First(), current)
+					if err != nil {
+						return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+					}
+
+					if diff.HasChanges() {
+						return cli.Exit("", exitCodeChanged)
+					}
+
+					return nil
+				},
 			},
-			&cli.StringFlag{
-				Name:     "output-file",
-				Value:    "",
-				Usage:    "write formatted output to file (optional)",
-				Required: false,
+			{
+				Name:  "config",
+				Usage: "manage the configuration",
+				Commands: []*cli.Command{
+					{
+						Name:   "validate",
+						Usage:  "validate the flags and every file they refer to without connecting",
+						Flags:  flagSet(sourceFlags(), connectionFlags(), commonFlags(), outputFlags()),
+						Before: rejectRootFlags,
+						Action: func(ctx context.Context, c *cli.Command) error {
+							if err := app.New().Validate(newAppConfig(c)); err != nil {
+								return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+							}
+
+							fmt.Println("Configuration is valid")
+							return nil
+						},
+					},
+				},
 			},
 		},
-		Action: func(ctx context.Context, c *cli.Command) error {
-			cfg := &config.AppConfig{
-				ConfigFile:       c.String("config"),
-				Domains:          c.String("domains"),
-				DomainsFile:      c.String("domains-file"),
-				DomainsFileSkip:  c.Int("skip"),
-				DomainsFileLimit: c.Int("limit"),
-				Timeout:          c.Int("timeout"),
-				Insecure:         c.Bool("insecure"),
-				OutputFormat:     c.String("output"),
-				OutputFile:       c.String("output-file"),
-			}
-
-			// Create a context that can be cancelled by signals
-			ctx, cancel := context.WithCancel(ctx)
-			defer cancel()
-
-			// Handle graceful shutdown
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
-			application := app.New()
-			if err := application.Run(ctx, cfg); err != nil {
-				return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
-			}
-
-			return nil
-		},
 	}
+}
 
-	if err := cliApp.Run(context.Background(), os.Args); err != nil {
-		os.Exit(1)
+// newAppConfig reads the flags of a command into the application configuration.
+// Flags the command does not declare keep their zero value.
+func newAppConfig(c *cli.Command) *config.AppConfig {
+	f := commandFlags{cmd: c}
+
+	return &config.AppConfig{
+		ConfigFile:       f.String("config"),
+		Domains:          f.String("domains"),
+		DomainsFile:      f.String("domains-file"),
+		DomainsFileSkip:  f.Int("skip"),
+		DomainsFileLimit: f.Int("limit"),
+		InspectPaths:     f.StringSlice("inspect"),
+		InspectKeyFiles:  f.StringSlice("inspect-key"),
+		KeystorePass:     f.String("keystore-password"),
+		KeystorePassFile: f.String("keystore-password-file"),
+		ManifestPaths:    f.StringSlice("manifests"),
+		Timeout:          f.Int("timeout"),
+		Proxy:            f.String("proxy"),
+		StartTLS:         f.String("starttls"),
+		AllIPs:           f.Bool("all-ips"),
+		ScanProtocols:    f.Bool("scan-protocols"),
+		OCSP:             f.Bool("ocsp"),
+		CRL:              f.Bool("crl"),
+		CRLFiles:         f.StringSlice("crl-file"),
+		CRLCacheDir:      f.String("crl-cache-dir"),
+		WarnDays:         f.Int("warn-days"),
+		CritDays:         f.Int("crit-days"),
+		Insecure:         f.Bool("insecure"),
+		CAFiles:          f.StringSlice("ca-file"),
+		CADir:            f.String("ca-dir"),
+		NoSystemRoots:    f.Bool("no-system-roots"),
+		ClientCert:       f.String("client-cert"),
+		ClientKey:        f.String("client-key"),
+		ClientPKCS12:     f.String("client-p12"),
+		ClientPKCS12Pass: f.String("client-p12-password"),
+		DisabledRules:    f.StringSlice("disable-rule"),
+		CT:               f.Bool("ct"),
+		CTLogList:        f.String("ct-log-list"),
+		PolicyFile:       f.String("policy"),
+		ShowChain:        f.Bool("show-chain"),
+		OutputFormat:     f.String("output"),
+		Fields:           f.StringSlice("fields"),
+		OutputFile:       f.String("output-file"),
+	}
+}
+
+// runCheck checks the configured sources once, prints the result and exits
+// with the code of the worst status
+func runCheck(ctx context.Context, cfg *config.AppConfig) error {
+	ctx, cancel := signalContext(ctx)
+	defer cancel()
+
+	application := app.New()
+	if err := application.Run(ctx, cfg); err != nil {
+		return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+	}
+
+	if code := application.ExitCode(); code != 0 {
+		return cli.Exit("", code)
+	}
+
+	return nil
+}
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM
+func signalContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	// Create a context that can be cancelled by signals
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigChan)
+		cancel()
 	}
 }
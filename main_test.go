@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestCommand_RootFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "declared root flag before subcommand",
+			args:    []string{"-d", "example.com", "check"},
+			wantErr: "flag --domains must be given after the ssl-certs-checker check command",
+		},
+		{
+			name:    "declared root flag before nested subcommand",
+			args:    []string{"--insecure", "config", "validate", "-d", "example.com"},
+			wantErr: "flag --insecure must be given after the ssl-certs-checker config validate command",
+		},
+		{
+			name:    "root flag the subcommand does not declare",
+			args:    []string{"check", "--inspect", "."},
+			wantErr: "flag --inspect is not supported by the ssl-certs-checker check command",
+		},
+		{
+			name: "flag after subcommand",
+			args: []string{"config", "validate", "--insecure", "-d", "example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCommand()
+			cmd.Writer = io.Discard
+			cmd.ErrWriter = io.Discard
+			cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+			err := cmd.Run(context.Background(), append([]string{"ssl-certs-checker"}, tt.args...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Run() unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
@@ -19,26 +21,202 @@ func New() *App {
 
 // Run executes the application with the given configuration
 func (a *App) Run(ctx context.Context, cfg *config.AppConfig) error {
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+	result, err := a.Check(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := a.formatter.FormatTo(result, cfg.OutputFormat, cfg.OutputFile); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
 	}
 
-	hosts, err := cfg.GetHosts()
+	a.mu.Lock()
+	a.status = result.WorstStatus()
+	a.mu.Unlock()
+
+	return nil
+}
+
+// Check runs the checks selected by the configuration and returns the result without printing it
+func (a *App) Check(ctx context.Context, cfg *config.AppConfig) (*cert.Result, error) {
+	policyRules, err := a.setup(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to get hosts: %w", err)
+		return nil, err
 	}
 
-	timeout := time.Duration(cfg.Timeout) * time.Second
-	a.checker = cert.New(timeout, cfg.Insecure)
+	return a.check(ctx, cfg, policyRules)
+}
+
+// check runs the checks with the checker created by setup and applies the policy rules
+func (a *App) check(ctx context.Context, cfg *config.AppConfig, policyRules []cert.Rule) (*cert.Result, error) {
+	result := &cert.Result{
+		Certificates: make([]cert.CertificateInfo, 0),
+		Errors:       make([]cert.ErrorInfo, 0),
+	}
+	if len(cfg.InspectPaths) > 0 {
+		opts, err := cfg.InspectOptions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore password: %w", err)
+		}
+
+		result, err = a.checker.Inspect(ctx, cfg.InspectPaths, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect certificates: %w", err)
+		}
+	} else if cfg.HasHostSource() {
+		hosts, err := cfg.GetHostSpecs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hosts: %w", err)
+		}
 
-	result, err := a.checker.CheckCertificates(ctx, hosts)
+		result, err = a.checker.CheckHosts(ctx, hosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check certificates: %w", err)
+		}
+	}
+
+	// Manifests are read from files only and reported alongside the other sources
+	if len(cfg.ManifestPaths) > 0 {
+		sources, errs := config.LoadManifests(cfg.ManifestPaths)
+		manifestResult, err := a.checker.InspectSources(ctx, sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check manifest certificates: %w", err)
+		}
+		result.Certificates = append(result.Certificates, manifestResult.Certificates...)
+		result.Errors = append(append(result.Errors, errs...), manifestResult.Errors...)
+	}
+
+	result.ApplyRules(policyRules)
+
+	return result, nil
+}
+
+// Validate checks the configuration and loads every file it refers to, without
+// connecting to any host
+func (a *App) Validate(cfg *config.AppConfig) error {
+	_, err := a.validate(cfg)
+	return err
+}
+
+// validate runs setup and checks the sources of the configuration. It returns
+// the rules of the policy file.
+func (a *App) validate(cfg *config.AppConfig) ([]cert.Rule, error) {
+	policyRules, err := a.setup(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to check certificates: %w", err)
+		return nil, err
 	}
 
-	if err := a.formatter.FormatTo(result, cfg.OutputFormat, cfg.OutputFile); err != nil {
-		return fmt.Errorf("failed to format output: %w", err)
+	if cfg.HasHostSource() {
+		if _, err := cfg.GetHostSpecs(); err != nil {
+			return nil, fmt.Errorf("failed to get hosts: %w", err)
+		}
 	}
 
-	return nil
+	if len(cfg.InspectPaths) > 0 {
+		if _, err := cfg.InspectOptions(); err != nil {
+			return nil, fmt.Errorf("failed to read keystore password: %w", err)
+		}
+	}
+
+	if len(cfg.ManifestPaths) > 0 {
+		if _, errs := config.LoadManifests(cfg.ManifestPaths); len(errs) > 0 {
+			return nil, fmt.Errorf("failed to load manifests: %s: %s", errs[0].Host, errs[0].Error)
+		}
+	}
+
+	return policyRules, nil
+}
+
+// setup validates the configuration, loads the files it refers to and creates
+// the checker. It returns the rules of the policy file.
+func (a *App) setup(cfg *config.AppConfig) ([]cert.Rule, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	if err := a.formatter.SetFields(cfg.Fields); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	roots, err := cert.LoadCertPool(cfg.CAPaths(), !cfg.NoSystemRoots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA certificates: %w", err)
+	}
+
+	rules, err := cfg.Rules()
+	if err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	var ctLogs *cert.CTLogList
+	if cfg.CTLogList != "" {
+		ctLogs, err = cert.LoadCTLogList(cfg.CTLogList)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CT log list: %w", err)
+		}
+	}
+
+	var policyRules []cert.Rule
+	if cfg.PolicyFile != "" {
+		policy, err := config.LoadPolicy(cfg.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy: %w", err)
+		}
+		policyRules = policy.CertRules()
+	}
+
+	var clientCert *tls.Certificate
+	if spec := cfg.ClientCertSpec(); !spec.IsZero() {
+		clientCert, err = cert.LoadClientCertificate(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+	}
+
+	var proxy *url.URL
+	if cfg.Proxy != "" {
+		proxy, err = cert.ParseProxyURL(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	a.checker = cert.NewWithOptions(cert.Options{
+		Timeout:  timeout,
+		Insecure: cfg.Insecure,
+		Thresholds: cert.Thresholds{
+			WarnDays: cfg.WarnDays,
+			CritDays: cfg.CritDays,
+		},
+		Roots:             roots,
+		ClientCertificate: clientCert,
+		IncludeChain:      cfg.ShowChain,
+		StartTLS:          cfg.StartTLS,
+		AllIPs:            cfg.AllIPs,
+		ScanProtocols:     cfg.ScanProtocols,
+		OCSP:              cfg.OCSP,
+		CRL:               cfg.CRL,
+		CRLFiles:          cfg.CRLFiles,
+		CRLCacheDir:       cfg.CRLCacheDir,
+		Proxy:             proxy,
+		Rules:             rules,
+		CT:                cfg.CT,
+		CTLogs:            ctLogs,
+	})
+
+	return policyRules, nil
+}
+
+// Status returns the worst certificate status seen by the last Run or Serve check
+func (a *App) Status() cert.Status {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.status
+}
+
+// ExitCode returns the process exit code derived from the last Run
+func (a *App) ExitCode() int {
+	return a.Status().ExitCode()
 }
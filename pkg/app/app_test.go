@@ -2,10 +2,12 @@ package app
 
 import (
 	"context"
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
 	"github.com/guessi/ssl-certs-checker/pkg/config"
 )
 
@@ -111,6 +113,180 @@ func TestApp_Run_NonExistentConfigFile(t *testing.T) {
 	}
 }
 
+func TestApp_Run_InvalidCAFile(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		Domains:      "example.com",
+		Timeout:      5,
+		CAFiles:      []string{caFile},
+		OutputFormat: "table",
+	}
+
+	err := app.Run(ctx, cfg)
+	if err == nil {
+		t.Error("Run() should return error for a CA file without certificates")
+	}
+}
+
+func TestApp_Run_UnknownField(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	cfg := &config.AppConfig{
+		Domains:      "example.com",
+		Timeout:      5,
+		OutputFormat: "table",
+		Fields:       []string{"host", "not_a_field"},
+	}
+
+	err := app.Run(ctx, cfg)
+	if err == nil {
+		t.Error("Run() should return error for an unknown table field")
+	}
+}
+
+func TestApp_Run_InvalidPolicy(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte("rules:\n  - id: a\n    require: {no_such_field: {equals: x}}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		Domains:      "example.com",
+		Timeout:      5,
+		OutputFormat: "table",
+		PolicyFile:   policyFile,
+	}
+
+	err := app.Run(ctx, cfg)
+	if err == nil {
+		t.Error("Run() should return error for an invalid policy file")
+	}
+}
+
+func TestApp_Run_Inspect(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	// An expired self-signed certificate, so the outcome shows in the exit code
+	dir := t.TempDir()
+	writeTestCertificate(t, filepath.Join(dir, "server.pem"), "inspected.example.com", true)
+
+	cfg := &config.AppConfig{
+		InspectPaths: []string{dir},
+		Timeout:      5,
+		Insecure:     true,
+		WarnDays:     30,
+		CritDays:     7,
+		OutputFormat: "json",
+		OutputFile:   filepath.Join(t.TempDir(), "result.json"),
+	}
+
+	if err := app.Run(ctx, cfg); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	if status := app.Status(); status != cert.StatusExpired {
+		t.Errorf("Status() = %s, want %s for an expired certificate file", status, cert.StatusExpired)
+	}
+}
+
+func TestApp_Run_Manifests(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	crt := base64.StdEncoding.EncodeToString(newTestCertificatePEM(t, "manifest.example.com", true))
+
+	// An expired certificate in a Secret, so the outcome shows in the exit code
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: manifest-tls
+type: kubernetes.io/tls
+data:
+  tls.crt: ` + crt + `
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: manifest
+spec:
+  secretName: manifest-tls
+  dnsNames:
+    - manifest.example.com
+`
+	manifestFile := filepath.Join(t.TempDir(), "tls.yaml")
+	if err := os.WriteFile(manifestFile, []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		ManifestPaths: []string{manifestFile},
+		Timeout:       5,
+		Insecure:      true,
+		WarnDays:      30,
+		CritDays:      7,
+		OutputFormat:  "json",
+		OutputFile:    filepath.Join(t.TempDir(), "result.json"),
+	}
+
+	if err := app.Run(ctx, cfg); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	if status := app.Status(); status != cert.StatusExpired {
+		t.Errorf("Status() = %s, want %s for an expired certificate in a Secret", status, cert.StatusExpired)
+	}
+}
+
+func TestApp_Validate(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "hosts.yaml")
+	if err := os.WriteFile(configFile, []byte("hosts:\n  - example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	invalidConfig := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalidConfig, []byte("hosts:\n  - host: bad host\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	invalidManifest := filepath.Join(dir, "secret.yaml")
+	manifest := "apiVersion: v1\nkind: Secret\nmetadata: {name: tls}\ntype: kubernetes.io/tls\ndata: {tls.crt: \"not base64!\"}\n"
+	if err := os.WriteFile(invalidManifest, []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.AppConfig
+		wantErr bool
+	}{
+		{name: "valid config file", cfg: config.AppConfig{ConfigFile: configFile, Timeout: 5}},
+		{name: "invalid host in config file", cfg: config.AppConfig{ConfigFile: invalidConfig, Timeout: 5}, wantErr: true},
+		{name: "unknown field", cfg: config.AppConfig{ConfigFile: configFile, Timeout: 5, Fields: []string{"nope"}}, wantErr: true},
+		{name: "invalid manifest", cfg: config.AppConfig{ManifestPaths: []string{invalidManifest}, Timeout: 5}, wantErr: true},
+		{name: "no source", cfg: config.AppConfig{Timeout: 5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestApp_Run_InvalidDomains(t *testing.T) {
 	app := New()
 	ctx := context.Background()
@@ -127,6 +303,10 @@ func TestApp_Run_InvalidDomains(t *testing.T) {
 	if err != nil {
 		t.Errorf("Run() should not return error for invalid domains, should handle gracefully: %v", err)
 	}
+
+	if code := app.ExitCode(); code != cert.StatusError.ExitCode() {
+		t.Errorf("ExitCode() = %d, want %d for hosts that failed to check", code, cert.StatusError.ExitCode())
+	}
 }
 
 func TestApp_Run_ContextCancellation(t *testing.T) {
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"sync"
+
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
 	"github.com/guessi/ssl-certs-checker/pkg/output"
 )
@@ -8,4 +10,9 @@ import (
 type App struct {
 	checker   *cert.Checker
 	formatter *output.Formatter
+
+	// mu guards the status and the latest result, which Serve updates while serving them
+	mu     sync.RWMutex
+	status cert.Status
+	latest *cert.Result
 }
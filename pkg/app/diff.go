@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+// Diff compares a baseline result file with the current result file or, when
+// current is empty, with a fresh check of the configuration, and prints the changes
+func (a *App) Diff(ctx context.Context, cfg *config.AppConfig, baseline, current string) (*cert.Diff, error) {
+	old, err := output.ReadResult(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+
+	var result *cert.Result
+	if current != "" {
+		result, err = output.ReadResult(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load current result: %w", err)
+		}
+	} else {
+		result, err = a.Check(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff := cert.DiffResults(old, result)
+	if err := a.formatter.FormatDiffTo(diff, cfg.OutputFormat, cfg.OutputFile); err != nil {
+		return nil, fmt.Errorf("failed to format output: %w", err)
+	}
+
+	return diff, nil
+}
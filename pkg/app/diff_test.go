@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+func TestApp_Diff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, result *cert.Result) string {
+		path := filepath.Join(dir, name)
+		if err := output.New().FormatTo(result, "json", path); err != nil {
+			t.Fatalf("Failed to write result: %v", err)
+		}
+		return path
+	}
+
+	notAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := write("baseline.json", &cert.Result{Certificates: []cert.CertificateInfo{
+		{Host: "example.com:443", SHA256Fingerprint: "AA", NotAfter: notAfter, Status: cert.StatusOK},
+	}})
+	renewed := write("renewed.json", &cert.Result{Certificates: []cert.CertificateInfo{
+		{Host: "example.com:443", SHA256Fingerprint: "BB", NotAfter: notAfter.AddDate(0, 3, 0), Status: cert.StatusOK},
+	}})
+
+	outputFile := filepath.Join(dir, "diff.json")
+	cfg := &config.AppConfig{OutputFormat: "json", OutputFile: outputFile}
+
+	diff, err := New().Diff(context.Background(), cfg, baseline, renewed)
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Change != cert.ChangeChanged || len(diff.Changes[0].Fields) != 2 {
+		t.Errorf("Diff() = %+v, want the fingerprint and expiry of example.com changed", diff.Changes)
+	}
+
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	var written cert.Diff
+	if err := json.Unmarshal(data, &written); err != nil || len(written.Changes) != 1 {
+		t.Errorf("Diff() output = %s, want the change as JSON", data)
+	}
+
+	diff, err = New().Diff(context.Background(), cfg, baseline, baseline)
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if diff.HasChanges() {
+		t.Errorf("Diff() of a result with itself = %+v, want no changes", diff.Changes)
+	}
+
+	if _, err := New().Diff(context.Background(), cfg, filepath.Join(dir, "missing.json"), renewed); err == nil {
+		t.Error("Diff() expected error for a missing baseline")
+	}
+}
+
+func TestApp_Diff_Check(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.pem")
+	writeTestCertificate(t, certFile, "served.example.com", false)
+
+	baseline := filepath.Join(dir, "baseline.json")
+	if err := output.New().FormatTo(&cert.Result{Certificates: []cert.CertificateInfo{}}, "json", baseline); err != nil {
+		t.Fatalf("Failed to write result: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		InspectPaths: []string{certFile},
+		Timeout:      5,
+		Insecure:     true,
+		OutputFormat: "json",
+		OutputFile:   filepath.Join(dir, "diff.json"),
+	}
+
+	diff, err := New().Diff(context.Background(), cfg, baseline, "")
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Host != certFile || diff.Changes[0].Change != cert.ChangeAdded {
+		t.Errorf("Diff() = %+v, want %s added", diff.Changes, certFile)
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestCertificatePEM creates a self-signed certificate for commonName, valid
+// for a year or, when expired is set, expired since yesterday
+func newTestCertificatePEM(t *testing.T, commonName string, expired bool) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().AddDate(0, 0, -1),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}
+	if expired {
+		tmpl.NotBefore = time.Now().AddDate(-1, 0, 0)
+		tmpl.NotAfter = time.Now().AddDate(0, 0, -1)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// writeTestCertificate writes a certificate from newTestCertificatePEM to path
+func writeTestCertificate(t *testing.T, path, commonName string, expired bool) {
+	t.Helper()
+
+	if err := os.WriteFile(path, newTestCertificatePEM(t, commonName, expired), 0644); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+}
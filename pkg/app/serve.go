@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+)
+
+const (
+	serveReadHeaderTimeout = 10 * time.Second
+	serveShutdownTimeout   = 5 * time.Second
+)
+
+// Serve checks the configuration every interval and serves the latest result
+// over HTTP until ctx is cancelled. A failed check keeps the previous result.
+// The files the configuration refers to are loaded once, when Serve starts.
+func (a *App) Serve(ctx context.Context, cfg *config.AppConfig, opts ServeOptions) error {
+	if opts.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	if cfg.OutputFormat != "json" && cfg.OutputFormat != "yaml" {
+		return fmt.Errorf("invalid output format for serve: %s (supported: json, yaml)", cfg.OutputFormat)
+	}
+
+	policyRules, err := a.validate(cfg)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           a.serveHandler(cfg.OutputFormat),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		a.refresh(ctx, cfg, policyRules)
+
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to shut down server: %w", err)
+			}
+			return nil
+		case err := <-serveErr:
+			return fmt.Errorf("server failed: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh runs one check with the checker created by Serve and publishes its result
+func (a *App) refresh(ctx context.Context, cfg *config.AppConfig, policyRules []cert.Rule) {
+	result, err := a.check(ctx, cfg, policyRules)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "Check failed: %v\n", err)
+		}
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latest = result
+	a.status = result.WorstStatus()
+}
+
+// serveHandler serves the latest result at / and a liveness probe at /healthz
+func (a *App) serveHandler(format string) http.Handler {
+	contentType := "application/json"
+	if format == "yaml" {
+		contentType = "application/yaml"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.RLock()
+		result := a.latest
+		a.mu.RUnlock()
+
+		if result == nil {
+			http.Error(w, "no check has completed yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := a.formatter.Render(result, format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
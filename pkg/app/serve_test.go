@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+)
+
+func TestApp_ServeHandler(t *testing.T) {
+	app := New()
+	handler := app.serveHandler("json")
+
+	tests := []struct {
+		name       string
+		path       string
+		latest     *cert.Result
+		wantStatus int
+	}{
+		{name: "health", path: "/healthz", wantStatus: http.StatusOK},
+		{name: "no result yet", path: "/", wantStatus: http.StatusServiceUnavailable},
+		{
+			name:       "latest result",
+			path:       "/",
+			latest:     &cert.Result{Certificates: []cert.CertificateInfo{{Host: "example.com:443", Status: cert.StatusOK}}},
+			wantStatus: http.StatusOK,
+		},
+		{name: "unknown path", path: "/metrics", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.latest = tt.latest
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, recorder.Code, tt.wantStatus)
+			}
+			if tt.latest == nil {
+				return
+			}
+
+			var got cert.Result
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("GET %s should return valid JSON: %v", tt.path, err)
+			}
+			if len(got.Certificates) != 1 || got.Certificates[0].Host != "example.com:443" {
+				t.Errorf("GET %s = %+v, want the latest result", tt.path, got)
+			}
+		})
+	}
+}
+
+func TestApp_Serve(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "server.pem")
+	writeTestCertificate(t, certFile, "served.example.com", true)
+
+	cfg := &config.AppConfig{
+		InspectPaths: []string{certFile},
+		Timeout:      5,
+		Insecure:     true,
+		WarnDays:     30,
+		CritDays:     7,
+		OutputFormat: "json",
+	}
+
+	invalid := []struct {
+		name string
+		cfg  config.AppConfig
+		opts ServeOptions
+	}{
+		{name: "zero interval", cfg: *cfg, opts: ServeOptions{Listen: "127.0.0.1:0"}},
+		{name: "table output", cfg: config.AppConfig{InspectPaths: cfg.InspectPaths, Timeout: 5, OutputFormat: "table"}, opts: ServeOptions{Listen: "127.0.0.1:0", Interval: time.Hour}},
+		{name: "invalid config", cfg: config.AppConfig{Timeout: 5, OutputFormat: "json"}, opts: ServeOptions{Listen: "127.0.0.1:0", Interval: time.Hour}},
+		{name: "invalid address", cfg: *cfg, opts: ServeOptions{Listen: "invalid::address", Interval: time.Hour}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().Serve(context.Background(), &tt.cfg, tt.opts); err == nil {
+				t.Error("Serve() expected error")
+			}
+		})
+	}
+
+	app := New()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := app.Serve(ctx, cfg, ServeOptions{Listen: "127.0.0.1:0", Interval: time.Hour}); err != nil {
+		t.Fatalf("Serve() unexpected error: %v", err)
+	}
+	if status := app.Status(); status != cert.StatusExpired {
+		t.Errorf("Status() = %s, want %s from the expired certificate", status, cert.StatusExpired)
+	}
+}
+
+func TestApp_Refresh(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "server.pem")
+	writeTestCertificate(t, certFile, "served.example.com", false)
+
+	cfg := &config.AppConfig{
+		InspectPaths: []string{certFile},
+		Timeout:      5,
+		WarnDays:     30,
+		CritDays:     7,
+		OutputFormat: "json",
+	}
+
+	app := New()
+	policyRules, err := app.validate(cfg)
+	if err != nil {
+		t.Fatalf("validate() unexpected error: %v", err)
+	}
+	checker := app.checker
+
+	for i := range 2 {
+		app.refresh(context.Background(), cfg, policyRules)
+
+		if app.checker != checker {
+			t.Fatalf("refresh %d replaced the checker created when serving started", i)
+		}
+		if app.latest == nil || len(app.latest.Certificates) != 1 {
+			t.Fatalf("refresh %d latest = %+v, want one certificate", i, app.latest)
+		}
+	}
+}
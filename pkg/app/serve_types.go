@@ -0,0 +1,11 @@
+package app
+
+import "time"
+
+// ServeOptions configures the HTTP server started by Serve
+type ServeOptions struct {
+	// Listen is the TCP address to listen on, such as ":8080"
+	Listen string
+	// Interval is the time between two checks
+	Interval time.Duration
+}
@@ -0,0 +1,90 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// keyUsageBits lists the key usage flags in the order they are reported
+var keyUsageBits = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "DigitalSignature"},
+	{x509.KeyUsageContentCommitment, "ContentCommitment"},
+	{x509.KeyUsageKeyEncipherment, "KeyEncipherment"},
+	{x509.KeyUsageDataEncipherment, "DataEncipherment"},
+	{x509.KeyUsageKeyAgreement, "KeyAgreement"},
+	{x509.KeyUsageCertSign, "CertSign"},
+	{x509.KeyUsageCRLSign, "CRLSign"},
+	{x509.KeyUsageEncipherOnly, "EncipherOnly"},
+	{x509.KeyUsageDecipherOnly, "DecipherOnly"},
+}
+
+// buildChain converts every presented certificate into a CertificateInfo, keeping the server's order
+func (c *Checker) buildChain(certs []*x509.Certificate, now time.Time) []CertificateInfo {
+	chain := make([]CertificateInfo, 0, len(certs))
+	for _, cert := range certs {
+		if cert == nil {
+			continue
+		}
+
+		info := newCertificateInfo("", cert)
+		c.thresholds.Evaluate(info, now)
+		chain = append(chain, *info)
+	}
+
+	return chain
+}
+
+// analyzeChain reports structural problems with the chain as presented by the server
+func analyzeChain(certs []*x509.Certificate) []string {
+	var issues []string
+
+	if len(certs) == 0 {
+		return issues
+	}
+
+	if certs[0].IsCA {
+		issues = append(issues, "first certificate is not the leaf")
+	}
+
+	for i, cert := range certs {
+		if i > 0 && isSelfSigned(cert) {
+			issues = append(issues, fmt.Sprintf("chain includes root certificate at position %d (%s)", i, cert.Subject.CommonName))
+		}
+
+		if i+1 < len(certs) && !bytes.Equal(cert.RawIssuer, certs[i+1].RawSubject) {
+			issues = append(issues, fmt.Sprintf("certificate at position %d is not issued by the next certificate in the chain", i))
+		}
+	}
+
+	if len(certs) == 1 && !isSelfSigned(certs[0]) {
+		issues = append(issues, "only the leaf certificate was presented (missing intermediates?)")
+	}
+
+	return issues
+}
+
+// isSelfSigned reports whether the certificate is its own issuer
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+
+	return cert.CheckSignatureFrom(cert) == nil
+}
+
+// keyUsageNames returns the names of the key usage flags that are set
+func keyUsageNames(usage x509.KeyUsage) []string {
+	var names []string
+	for _, bit := range keyUsageBits {
+		if usage&bit.usage != 0 {
+			names = append(names, bit.name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,117 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeChain(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	leaf := newTestLeaf(t, intermediate, "example.com")
+
+	tests := []struct {
+		name       string
+		certs      []*x509.Certificate
+		wantIssues []string
+	}{
+		{
+			name:  "well-formed chain",
+			certs: []*x509.Certificate{leaf.Cert, intermediate.Cert},
+		},
+		{
+			name:       "chain includes root",
+			certs:      []*x509.Certificate{leaf.Cert, intermediate.Cert, root.Cert},
+			wantIssues: []string{"chain includes root certificate at position 2"},
+		},
+		{
+			name:       "out of order chain",
+			certs:      []*x509.Certificate{intermediate.Cert, leaf.Cert},
+			wantIssues: []string{"first certificate is not the leaf", "position 0 is not issued by the next"},
+		},
+		{
+			name:       "leaf only",
+			certs:      []*x509.Certificate{leaf.Cert},
+			wantIssues: []string{"only the leaf certificate was presented"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := analyzeChain(tt.certs)
+
+			if len(issues) != len(tt.wantIssues) {
+				t.Fatalf("analyzeChain() issues = %v, want %d issue(s)", issues, len(tt.wantIssues))
+			}
+
+			for i, want := range tt.wantIssues {
+				if !strings.Contains(issues[i], want) {
+					t.Errorf("analyzeChain() issue[%d] = %q, want it to contain %q", i, issues[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyUsageNames(t *testing.T) {
+	got := keyUsageNames(x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign)
+	want := []string{"DigitalSignature", "CertSign"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keyUsageNames() = %v, want %v", got, want)
+	}
+
+	if names := keyUsageNames(0); len(names) != 0 {
+		t.Errorf("keyUsageNames(0) = %v, want empty", names)
+	}
+}
+
+func TestGetCertInfoByHost_IncludeChain(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	leaf := newTestLeaf(t, intermediate, "example.com")
+
+	host, port := startTestTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{leaf.tlsCertificate(intermediate)},
+	})
+
+	checker := NewWithOptions(Options{
+		Timeout:      5 * time.Second,
+		Insecure:     true,
+		Thresholds:   DefaultThresholds(),
+		IncludeChain: true,
+	})
+
+	info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+	if err != nil {
+		t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+	}
+
+	if len(info.Chain) != 2 {
+		t.Fatalf("getCertInfoByHost() chain length = %d, want 2", len(info.Chain))
+	}
+
+	if info.Chain[0].CommonName != "example.com" || info.Chain[0].IsCA {
+		t.Errorf("chain[0] = %s (CA: %v), want leaf example.com", info.Chain[0].CommonName, info.Chain[0].IsCA)
+	}
+
+	if info.Chain[1].CommonName != "Test Intermediate" || !info.Chain[1].IsCA {
+		t.Errorf("chain[1] = %s (CA: %v), want CA Test Intermediate", info.Chain[1].CommonName, info.Chain[1].IsCA)
+	}
+
+	if info.Chain[1].Status != StatusOK {
+		t.Errorf("chain[1] status = %v, want %v", info.Chain[1].Status, StatusOK)
+	}
+
+	if len(info.ChainIssues) != 0 {
+		t.Errorf("getCertInfoByHost() chain issues = %v, want none", info.ChainIssues)
+	}
+
+	if info.SerialNumber == "" {
+		t.Error("getCertInfoByHost() serial number is empty")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,16 +19,74 @@ const (
 	MaxConcurrency = 10
 )
 
-// New creates a new certificate checker
+// New creates a new certificate checker using the default expiry thresholds
 func New(timeout time.Duration, insecure bool) *Checker {
-	return &Checker{
-		timeout:  timeout,
-		insecure: insecure,
+	return NewWithOptions(Options{
+		Timeout:    timeout,
+		Insecure:   insecure,
+		Thresholds: DefaultThresholds(),
+	})
+}
+
+// NewWithOptions creates a new certificate checker from the given options
+func NewWithOptions(opts Options) *Checker {
+	checker := &Checker{
+		timeout:      opts.Timeout,
+		insecure:     opts.Insecure,
+		thresholds:   opts.Thresholds,
+		roots:        opts.Roots,
+		clientCert:   opts.ClientCertificate,
+		includeChain: opts.IncludeChain,
+		scan:         opts.ScanProtocols,
+		startTLS:     opts.StartTLS,
+		allIPs:       opts.AllIPs,
+		resolver:     opts.Resolver,
+		ocsp:         opts.OCSP,
+		crl:          opts.CRL,
+		crls:         newCRLStore(opts.CRLCacheDir, opts.CRLFiles),
+		rules:        opts.Rules,
+		ct:           opts.CT || opts.CTLogs != nil,
+		ctLogs:       opts.CTLogs,
+
+		hostRoots:       make(map[string]*x509.CertPool),
+		hostClientCerts: make(map[ClientCertSpec]*tls.Certificate),
+	}
+	if checker.resolver == nil {
+		checker.resolver = net.DefaultResolver
+	}
+	if checker.rules == nil {
+		checker.rules = DefaultRules()
+	}
+
+	forward := opts.Dialer
+	if forward == nil {
+		forward = &net.Dialer{Timeout: opts.Timeout}
 	}
+	proxy := newProxyDialer(opts.Proxy, forward, checker.resolver)
+	checker.dialer = proxy
+	checker.httpClient = &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			Proxy:       proxy.httpProxy,
+			DialContext: forward.DialContext,
+		},
+	}
+
+	return checker
 }
 
 // CheckCertificates checks SSL certificates for multiple hosts concurrently
 func (c *Checker) CheckCertificates(ctx context.Context, hosts []string) (*Result, error) {
+	specs := make([]HostSpec, len(hosts))
+	for i, host := range hosts {
+		specs[i] = HostSpec{Host: host}
+	}
+
+	return c.CheckHosts(ctx, specs)
+}
+
+// CheckHosts checks SSL certificates for multiple host entries concurrently
+func (c *Checker) CheckHosts(ctx context.Context, hosts []HostSpec) (*Result, error) {
 	if len(hosts) == 0 {
 		return nil, fmt.Errorf("no hosts provided")
 	}
@@ -43,133 +102,274 @@ func (c *Checker) CheckCertificates(ctx context.Context, hosts []string) (*Resul
 	// Limit concurrent connections to be respectful to target servers
 	semaphore := make(chan struct{}, MaxConcurrency)
 
-	for _, hostStr := range hosts {
+	// Certificate indices per original host, used to compare endpoints in --all-ips mode
+	groups := make(map[string][]int)
+
+	for _, spec := range hosts {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
-		hostname, port, err := parseHost(hostStr)
+		target, err := resolveTarget(spec, c.startTLS)
 		if err != nil {
 			mutex.Lock()
 			result.Errors = append(result.Errors, ErrorInfo{
-				Host:  hostStr,
+				Host:  spec.label(),
 				Error: fmt.Sprintf("invalid host format: %v", err),
 			})
 			mutex.Unlock()
 			continue
 		}
 
+		if !c.allIPs {
+			wg.Add(1)
+			go func(target Target) {
+				defer wg.Done()
+				c.checkTarget(ctx, target, "", semaphore, &mutex, result, nil)
+			}(target)
+			continue
+		}
+
 		wg.Add(1)
-		go func(host string, p int) {
+		go func(target Target) {
 			defer wg.Done()
 
-			semaphore <- struct{}{}        // Acquire
-			defer func() { <-semaphore }() // Release
-
-			certInfo, err := c.getCertInfoByHost(ctx, host, p)
-
-			mutex.Lock()
+			endpoints, err := c.expandTarget(ctx, target)
 			if err != nil {
+				mutex.Lock()
 				result.Errors = append(result.Errors, ErrorInfo{
-					Host:  fmt.Sprintf("%s:%d", host, p),
+					Host:  target.String(),
 					Error: err.Error(),
 				})
-			} else if certInfo != nil {
-				result.Certificates = append(result.Certificates, *certInfo)
+				mutex.Unlock()
+				return
 			}
-			mutex.Unlock()
-		}(hostname, port)
+
+			for _, endpoint := range endpoints {
+				wg.Add(1)
+				go func(endpoint Target) {
+					defer wg.Done()
+					c.checkTarget(ctx, endpoint, target.String(), semaphore, &mutex, result, groups)
+				}(endpoint)
+			}
+		}(target)
 	}
 
 	wg.Wait()
+
+	if c.allIPs {
+		markEndpointMismatches(result, groups)
+	}
+
 	return result, nil
 }
 
+// checkTarget checks a single target and records the outcome on result.
+// When groups is non-nil the certificate index is recorded under group.
+func (c *Checker) checkTarget(ctx context.Context, target Target, group string, semaphore chan struct{}, mutex *sync.Mutex, result *Result, groups map[string][]int) {
+	semaphore <- struct{}{}        // Acquire
+	defer func() { <-semaphore }() // Release
+
+	certInfo, err := c.getCertInfoByHost(ctx, target)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err != nil {
+		result.Errors = append(result.Errors, ErrorInfo{
+			Host:  target.String(),
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if certInfo != nil {
+		result.Certificates = append(result.Certificates, *certInfo)
+		if groups != nil {
+			groups[group] = append(groups[group], len(result.Certificates)-1)
+		}
+	}
+}
+
 // getCertInfoByHost get SSL certificate info by host
-func (c *Checker) getCertInfoByHost(ctx context.Context, hostname string, port int) (*CertificateInfo, error) {
-	if hostname == "" {
+func (c *Checker) getCertInfoByHost(ctx context.Context, target Target) (*CertificateInfo, error) {
+	if target.Hostname == "" {
 		return nil, fmt.Errorf("hostname cannot be empty")
 	}
 
-	certs, err := c.getPeerCertificates(ctx, hostname, port)
+	roots, err := c.rootsFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCert, err := c.clientCertificateFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := parsePins(target.Pins)
 	if err != nil {
 		return nil, err
 	}
 
+	clientAuth := &ClientAuthInfo{}
+	state, connection, err := c.getConnectionState(ctx, target, c.newTLSConfig(target, clientCert, clientAuth))
+	if err != nil {
+		if clientAuth.Requested && !clientAuth.Sent {
+			return nil, fmt.Errorf("%w (server requested a client certificate, none configured)", err)
+		}
+		return nil, err
+	}
+	certs := state.PeerCertificates
+
 	// Find the first non-CA certificate (leaf certificate)
 	for _, cert := range certs {
 		if cert == nil || cert.IsCA {
 			continue
 		}
 
-		return &CertificateInfo{
-			Host:               fmt.Sprintf("%s:%d", hostname, port),
-			CommonName:         cert.Subject.CommonName,
-			DNSNames:           cert.DNSNames,
-			NotBefore:          cert.NotBefore,
-			NotAfter:           cert.NotAfter,
-			PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
-			Issuer:             cert.Issuer.CommonName,
-		}, nil
+		now := time.Now()
+		info := newCertificateInfo(target.String(), cert)
+		info.Connection = connection
+		c.thresholds.Evaluate(info, now)
+		c.verifyCertificate(info, cert, certs, roots, target.VerifyName(), now)
+		if len(pins) > 0 {
+			checkPins(info, certs, pins)
+		}
+		checkHostnames(info, cert, target.CoverageNames())
+
+		// Outside of insecure mode an untrusted chain or a name the certificate
+		// does not cover is as bad as an unreachable host
+		if !c.insecure && untrusted(info, cert, now) {
+			info.Status = info.Status.Worse(StatusError)
+		}
+		if !c.insecure && !info.HostnameMatch {
+			info.Status = info.Status.Worse(StatusError)
+		}
+		// Pins are an explicit per-host assertion, so a mismatch fails even in insecure mode
+		if info.VerificationFailure == VerifyFailurePinMismatch {
+			info.Status = info.Status.Worse(StatusError)
+		}
+
+		if clientAuth.Requested || clientCert != nil {
+			info.ClientAuth = clientAuth
+		}
+
+		if c.scan {
+			info.Protocols = c.scanProtocols(ctx, target, clientCert, state)
+		}
+
+		c.checkOCSP(ctx, info, cert, certs, state.OCSPResponse)
+		c.checkCRL(ctx, info, cert, certs)
+		c.checkSCTs(info, cert, certs, state)
+		ApplyRules(info, c.rules)
+
+		if c.includeChain {
+			info.Chain = c.buildChain(certs, now)
+			info.ChainIssues = analyzeChain(certs)
+		}
+
+		return info, nil
 	}
 
 	return nil, fmt.Errorf("no valid leaf certificate found")
 }
 
-// getPeerCertificates retrieves raw certificates from the server
-func (c *Checker) getPeerCertificates(ctx context.Context, hostname string, port int) ([]*x509.Certificate, error) {
-	// Create a context with timeout for the entire operation
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-
-	dialer := &net.Dialer{
-		Timeout: c.timeout,
+// newCertificateInfo extracts the reported metadata from a parsed certificate
+func newCertificateInfo(host string, cert *x509.Certificate) *CertificateInfo {
+	info := &CertificateInfo{
+		Host:               host,
+		CommonName:         cert.Subject.CommonName,
+		DNSNames:           cert.DNSNames,
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
+		Issuer:             cert.Issuer.CommonName,
+		Subject:            cert.Subject.String(),
+		SerialNumber:       formatSerial(cert),
+		IsCA:               cert.IsCA,
+		KeyUsage:           keyUsageNames(cert.KeyUsage),
 	}
+	addCertificateDetails(info, cert)
+
+	return info
+}
 
+// newTLSConfig builds the client configuration for target. A CertificateRequest
+// from the server is answered with clientCert and recorded on clientAuth.
+func (c *Checker) newTLSConfig(target Target, clientCert *tls.Certificate, clientAuth *ClientAuthInfo) *tls.Config {
+	// Verification is done separately by verifyCertificate so that metadata
+	// is still reported for hosts with broken chains
 	tlsConfig := &tls.Config{
-		ServerName:         hostname,
-		InsecureSkipVerify: c.insecure,
+		ServerName:         target.SNI(),
+		InsecureSkipVerify: true,
+
+		GetClientCertificate: clientAuthRecorder(clientCert, clientAuth),
+	}
+	if target.Negotiator.Name() == DefaultScheme {
+		tlsConfig.NextProtos = alpnProtocols
 	}
 
-	address := formatAddress(hostname, port)
+	return tlsConfig
+}
+
+// getConnectionState performs the handshake and returns the negotiated connection state
+// together with the connection details reported to the user
+func (c *Checker) getConnectionState(ctx context.Context, target Target, tlsConfig *tls.Config) (*tls.ConnectionState, *ConnectionInfo, error) {
+	// Create a context with timeout for the entire operation
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
 
-	// Use DialContext to respect context cancellation
-	conn, err := tls.DialWithDialer(dialer, Protocol, address, tlsConfig)
+	address := formatAddress(target.Hostname, target.Port)
+
+	connectStart := time.Now()
+	rawConn, err := c.dialer.DialContext(ctxWithTimeout, Protocol, address)
 	if err != nil {
 		// Check if the error is due to context cancellation
 		select {
 		case <-ctxWithTimeout.Done():
-			return nil, fmt.Errorf("connection to %s timed out or was cancelled: %w", address, ctxWithTimeout.Err())
+			return nil, nil, fmt.Errorf("connection to %s timed out or was cancelled: %w", address, ctxWithTimeout.Err())
 		default:
-			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+			return nil, nil, fmt.Errorf("failed to connect to %s: %w", address, err)
 		}
 	}
+	connectTime := time.Since(connectStart)
 	defer func() {
-		if closeErr := conn.Close(); closeErr != nil {
+		if closeErr := rawConn.Close(); closeErr != nil {
 			// Log the close error, but don't override the main error
 			// In a production environment, you might want to use a proper logger here
 		}
 	}()
 
-	// Check context cancellation before handshake
-	select {
-	case <-ctxWithTimeout.Done():
-		return nil, ctxWithTimeout.Err()
-	default:
+	if deadline, ok := ctxWithTimeout.Deadline(); ok {
+		_ = rawConn.SetDeadline(deadline)
+	}
+
+	if err := target.Negotiator.Negotiate(rawConn, target.VerifyName()); err != nil {
+		return nil, nil, fmt.Errorf("%s negotiation failed for %s: %w", target.Negotiator.Name(), address, err)
+	}
+
+	conn := tls.Client(rawConn, tlsConfig)
+	handshakeStart := time.Now()
+	if err := conn.HandshakeContext(ctxWithTimeout); err != nil {
+		return nil, nil, fmt.Errorf("TLS handshake failed for %s: %w", address, err)
 	}
+	handshakeTime := time.Since(handshakeStart)
 
-	if err := conn.Handshake(); err != nil {
-		return nil, fmt.Errorf("TLS handshake failed for %s: %w", address, err)
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, nil, fmt.Errorf("no peer certificates found for %s", address)
 	}
 
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) == 0 {
-		return nil, fmt.Errorf("no peer certificates found for %s", address)
+	proxy := ""
+	if proxyURL := c.dialer.proxyFor(address); proxyURL != nil {
+		proxy = proxyURL.Scheme + "://" + proxyURL.Host
 	}
+	connection := newConnectionInfo(&state, rawConn.RemoteAddr().String(), proxy, connectTime, handshakeTime)
 
-	return certs, nil
+	return &state, connection, nil
 }
 
 // formatAddress formats hostname and port into a proper address string
@@ -184,6 +384,12 @@ func formatAddress(hostname string, port int) string {
 
 // parseHost parses a host string into hostname and port
 func parseHost(hostStr string) (hostname string, port int, err error) {
+	return parseHostWithDefaultPort(hostStr, DefaultPort)
+}
+
+// parseHostWithDefaultPort parses a host string into hostname and port,
+// using defaultPort when the string does not carry one
+func parseHostWithDefaultPort(hostStr string, defaultPort int) (hostname string, port int, err error) {
 	hostStr = strings.TrimSpace(hostStr)
 	if hostStr == "" {
 		return "", 0, fmt.Errorf("host cannot be empty")
@@ -203,7 +409,7 @@ func parseHost(hostStr string) (hostname string, port int, err error) {
 
 		remainder := hostStr[closeBracket+1:]
 		if remainder == "" {
-			return hostname, DefaultPort, nil
+			return hostname, defaultPort, nil
 		}
 
 		if !strings.HasPrefix(remainder, ":") {
@@ -212,7 +418,7 @@ func parseHost(hostStr string) (hostname string, port int, err error) {
 
 		portStr := strings.TrimSpace(remainder[1:])
 		if portStr == "" {
-			return hostname, DefaultPort, nil
+			return hostname, defaultPort, nil
 		}
 
 		p, err := strconv.Atoi(portStr)
@@ -233,7 +439,7 @@ func parseHost(hostStr string) (hostname string, port int, err error) {
 		// Try to determine if it's an IPv6 address
 		if strings.Count(hostStr, ":") > 1 {
 			// Likely IPv6 address without brackets
-			return hostStr, DefaultPort, nil
+			return hostStr, defaultPort, nil
 		}
 		return "", 0, fmt.Errorf("invalid host format (too many colons): %s", hostStr)
 	}
@@ -243,7 +449,7 @@ func parseHost(hostStr string) (hostname string, port int, err error) {
 		return "", 0, fmt.Errorf("hostname cannot be empty")
 	}
 
-	port = DefaultPort
+	port = defaultPort
 	if len(parts) == 2 {
 		portStr := strings.TrimSpace(parts[1])
 		if portStr != "" {
@@ -1,30 +1,172 @@
 package cert
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
 type CertificateInfo struct {
-	Host               string    `json:"host"`
-	CommonName         string    `json:"common_name"`
-	DNSNames           []string  `json:"dns_names"`
-	NotBefore          time.Time `json:"not_before"`
-	NotAfter           time.Time `json:"not_after"`
-	PublicKeyAlgorithm string    `json:"public_key_algorithm"`
-	Issuer             string    `json:"issuer"`
+	Host               string    `json:"host" yaml:"host"`
+	CommonName         string    `json:"common_name" yaml:"common_name"`
+	DNSNames           []string  `json:"dns_names" yaml:"dns_names"`
+	NotBefore          time.Time `json:"not_before" yaml:"not_before"`
+	NotAfter           time.Time `json:"not_after" yaml:"not_after"`
+	PublicKeyAlgorithm string    `json:"public_key_algorithm" yaml:"public_key_algorithm"`
+	Issuer             string    `json:"issuer" yaml:"issuer"`
+	DaysRemaining      int       `json:"days_remaining" yaml:"days_remaining"`
+	Status             Status    `json:"status" yaml:"status"`
+	Subject            string    `json:"subject,omitempty" yaml:"subject,omitempty"`
+	SerialNumber       string    `json:"serial_number,omitempty" yaml:"serial_number,omitempty"`
+	IsCA               bool      `json:"is_ca,omitempty" yaml:"is_ca,omitempty"`
+	KeyUsage           []string  `json:"key_usage,omitempty" yaml:"key_usage,omitempty"`
+
+	// Audit details
+	IssuerDN               string   `json:"issuer_dn,omitempty" yaml:"issuer_dn,omitempty"`
+	SHA1Fingerprint        string   `json:"sha1_fingerprint,omitempty" yaml:"sha1_fingerprint,omitempty"`
+	SHA256Fingerprint      string   `json:"sha256_fingerprint,omitempty" yaml:"sha256_fingerprint,omitempty"`
+	SPKISHA256             string   `json:"spki_sha256,omitempty" yaml:"spki_sha256,omitempty"`
+	SignatureAlgorithm     string   `json:"signature_algorithm,omitempty" yaml:"signature_algorithm,omitempty"`
+	KeySize                int      `json:"key_size,omitempty" yaml:"key_size,omitempty"`
+	KeyCurve               string   `json:"key_curve,omitempty" yaml:"key_curve,omitempty"`
+	ExtKeyUsage            []string `json:"ext_key_usage,omitempty" yaml:"ext_key_usage,omitempty"`
+	IPAddresses            []string `json:"ip_addresses,omitempty" yaml:"ip_addresses,omitempty"`
+	EmailAddresses         []string `json:"email_addresses,omitempty" yaml:"email_addresses,omitempty"`
+	URIs                   []string `json:"uris,omitempty" yaml:"uris,omitempty"`
+	PolicyOIDs             []string `json:"policy_oids,omitempty" yaml:"policy_oids,omitempty"`
+	OCSPServers            []string `json:"ocsp_servers,omitempty" yaml:"ocsp_servers,omitempty"`
+	IssuingCertificateURLs []string `json:"issuing_certificate_urls,omitempty" yaml:"issuing_certificate_urls,omitempty"`
+	CRLDistributionPoints  []string `json:"crl_distribution_points,omitempty" yaml:"crl_distribution_points,omitempty"`
+
+	Verified            bool          `json:"verified" yaml:"verified"`
+	VerificationFailure VerifyFailure `json:"verification_failure,omitempty" yaml:"verification_failure,omitempty"`
+	VerificationError   string        `json:"verification_error,omitempty" yaml:"verification_error,omitempty"`
+	// PinMatched is set when the host has pins and the presented chain matches one of them
+	PinMatched bool `json:"pin_matched,omitempty" yaml:"pin_matched,omitempty"`
+
+	// HostnameMatch reports whether the SANs cover every expected name, checked even in insecure mode
+	HostnameMatch bool     `json:"hostname_match" yaml:"hostname_match"`
+	MissingNames  []string `json:"missing_names,omitempty" yaml:"missing_names,omitempty"`
+
+	// KeyMatch reports whether an inspected certificate's private key was found next to it
+	KeyMatch *KeyMatchInfo `json:"key_match,omitempty" yaml:"key_match,omitempty"`
+
+	// Findings are the weak cryptography and policy rules the certificate violates
+	Findings []Finding `json:"findings,omitempty" yaml:"findings,omitempty"`
+
+	// Chain holds every certificate presented by the server, in the order it was sent
+	Chain       []CertificateInfo `json:"chain,omitempty" yaml:"chain,omitempty"`
+	ChainIssues []string          `json:"chain_issues,omitempty" yaml:"chain_issues,omitempty"`
+
+	// OCSP holds the revocation status from a stapled response or the OCSP responder
+	OCSP *OCSPInfo `json:"ocsp,omitempty" yaml:"ocsp,omitempty"`
+
+	// CRL holds the revocation status from the certificate's CRL
+	CRL *CRLInfo `json:"crl,omitempty" yaml:"crl,omitempty"`
+
+	// Connection describes the negotiated TLS connection
+	Connection *ConnectionInfo `json:"connection,omitempty" yaml:"connection,omitempty"`
+
+	// Protocols is the accepted TLS version and cipher suite matrix, with --scan-protocols
+	Protocols *ProtocolScan `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+
+	// SCTs are the signed certificate timestamps delivered for the leaf
+	SCTs []SCTInfo `json:"scts,omitempty" yaml:"scts,omitempty"`
+	// CT is the leaf's compliance with the browser CT policy, with CT checking enabled
+	CT *CTPolicy `json:"ct,omitempty" yaml:"ct,omitempty"`
+
+	// ClientAuth reports whether the server requested a client certificate
+	ClientAuth *ClientAuthInfo `json:"client_auth,omitempty" yaml:"client_auth,omitempty"`
+
+	// EndpointMismatch is set in all-IPs mode when addresses of the same host serve different certificates
+	EndpointMismatch bool `json:"endpoint_mismatch,omitempty" yaml:"endpoint_mismatch,omitempty"`
 }
 
 type ErrorInfo struct {
-	Host  string `json:"host"`
-	Error string `json:"error"`
+	Host  string `json:"host" yaml:"host"`
+	Error string `json:"error" yaml:"error"`
 }
 
 type Result struct {
-	Certificates []CertificateInfo `json:"certificates"`
-	Errors       []ErrorInfo       `json:"errors,omitempty"`
+	Certificates []CertificateInfo `json:"certificates" yaml:"certificates"`
+	Errors       []ErrorInfo       `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Options configures a Checker
+type Options struct {
+	Timeout    time.Duration
+	Insecure   bool
+	Thresholds Thresholds
+
+	// Roots is the pool used to verify presented chains; nil means the system roots.
+	// Hosts with their own CA are verified against that CA only.
+	Roots *x509.CertPool
+
+	// IncludeChain records the full presented chain on each result
+	IncludeChain bool
+	// ScanProtocols probes every TLS version and cipher suite the server accepts
+	ScanProtocols bool
+	// ClientCertificate is presented when a server requests one; hosts may override it
+	ClientCertificate *tls.Certificate
+
+	// StartTLS is the scheme applied to hosts given without one (e.g. "smtp"); empty means https
+	StartTLS string
+
+	// OCSP queries the responder listed in the leaf's AIA extension
+	OCSP bool
+
+	// CRL downloads the CRLs listed in the leaf's distribution points
+	CRL bool
+	// CRLFiles are local CRLs (DER or PEM) checked for every certificate, for offline use
+	CRLFiles []string
+	// CRLCacheDir caches downloaded CRLs on disk; empty means DefaultCRLCacheDir
+	CRLCacheDir string
+
+	// Proxy tunnels every connection through an HTTP CONNECT or SOCKS5 proxy,
+	// except for hosts matching NO_PROXY; nil means HTTPS_PROXY/ALL_PROXY
+	Proxy *url.URL
+	// Dialer opens TCP connections, to targets or to the proxy; nil means a net.Dialer
+	Dialer Dialer
+
+	// AllIPs checks every resolved address of a hostname instead of the one the dialer picks
+	AllIPs bool
+	// Resolver looks up addresses in AllIPs mode and for socks5 proxies; nil means net.DefaultResolver
+	Resolver Resolver
+
+	// Rules are evaluated against every leaf certificate; nil means DefaultRules
+	Rules []Rule
+
+	// CT evaluates the browser Certificate Transparency policy for every leaf
+	CT bool
+	// CTLogs verifies SCT signatures and names their logs; nil leaves SCTs unverified
+	CTLogs *CTLogList
 }
 
 type Checker struct {
-	timeout  time.Duration
-	insecure bool
+	timeout      time.Duration
+	insecure     bool
+	thresholds   Thresholds
+	roots        *x509.CertPool
+	clientCert   *tls.Certificate
+	includeChain bool
+	scan         bool
+	startTLS     string
+	allIPs       bool
+	resolver     Resolver
+	ocsp         bool
+	crl          bool
+	crls         *crlStore
+	dialer       *proxyDialer
+	httpClient   *http.Client
+	rules        []Rule
+	ct           bool
+	ctLogs       *CTLogList
+
+	// loadMutex guards the per-host roots and client certificates loaded on first use
+	loadMutex       sync.Mutex
+	hostRoots       map[string]*x509.CertPool
+	hostClientCerts map[ClientCertSpec]*tls.Certificate
 }
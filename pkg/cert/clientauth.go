@@ -0,0 +1,117 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"os"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// IsZero reports whether no client certificate is configured
+func (s ClientCertSpec) IsZero() bool {
+	return s.CertFile == "" && s.KeyFile == "" && s.PKCS12File == ""
+}
+
+// LoadClientCertificate loads the client certificate described by spec
+func LoadClientCertificate(spec ClientCertSpec) (*tls.Certificate, error) {
+	if spec.PKCS12File != "" {
+		if spec.CertFile != "" || spec.KeyFile != "" {
+			return nil, fmt.Errorf("PKCS#12 bundle and PEM certificate/key are mutually exclusive")
+		}
+		return loadPKCS12Certificate(spec.PKCS12File, spec.PKCS12Password)
+	}
+
+	if spec.CertFile == "" || spec.KeyFile == "" {
+		return nil, fmt.Errorf("client certificate and key must be specified together")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(spec.CertFile, spec.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load client certificate: %w", err)
+	}
+
+	return &certificate, nil
+}
+
+// loadPKCS12Certificate decodes a PKCS#12 bundle into a tls.Certificate
+func loadPKCS12Certificate(path, password string) (*tls.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read PKCS#12 file: %w", err)
+	}
+
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode PKCS#12 file: %w", err)
+	}
+
+	certificate := &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, ca := range caCerts {
+		certificate.Certificate = append(certificate.Certificate, ca.Raw)
+	}
+
+	return certificate, nil
+}
+
+// clientCertificateFor returns the client certificate presented to target,
+// loading and caching its per-host certificate the first time it is needed
+func (c *Checker) clientCertificateFor(target Target) (*tls.Certificate, error) {
+	if target.ClientCert.IsZero() {
+		return c.clientCert, nil
+	}
+
+	c.loadMutex.Lock()
+	defer c.loadMutex.Unlock()
+
+	if certificate, ok := c.hostClientCerts[target.ClientCert]; ok {
+		return certificate, nil
+	}
+
+	certificate, err := LoadClientCertificate(target.ClientCert)
+	if err != nil {
+		return nil, err
+	}
+	c.hostClientCerts[target.ClientCert] = certificate
+
+	return certificate, nil
+}
+
+// clientAuthRecorder answers the server's CertificateRequest and remembers what it asked for
+func clientAuthRecorder(certificate *tls.Certificate, info *ClientAuthInfo) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(request *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		info.Requested = true
+		info.AcceptableCAs = nil
+		for _, raw := range request.AcceptableCAs {
+			info.AcceptableCAs = append(info.AcceptableCAs, formatDistinguishedName(raw))
+		}
+
+		if certificate == nil {
+			// An empty certificate tells the server we have none to offer
+			info.Sent = false
+			return &tls.Certificate{}, nil
+		}
+
+		info.Sent = true
+		return certificate, nil
+	}
+}
+
+// formatDistinguishedName renders a DER encoded distinguished name
+func formatDistinguishedName(raw []byte) string {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(raw, &rdns); err != nil || len(rest) > 0 {
+		return fmt.Sprintf("%X", raw)
+	}
+
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdns)
+
+	return name.String()
+}
@@ -0,0 +1,241 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// newTestClientCert creates a client authentication certificate signed by parent
+func newTestClientCert(t *testing.T, parent *testCert) *testCert {
+	t.Helper()
+
+	return issueTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "test-client"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, parent)
+}
+
+// writeTestKeyPair writes the certificate and its PKCS#8 key as PEM files
+func writeTestKeyPair(t *testing.T, dir string, tc *testCert) (string, string) {
+	t.Helper()
+
+	certFile := filepath.Join(dir, "client.crt")
+	writeTestPEM(t, certFile, tc)
+
+	der, err := x509.MarshalPKCS8PrivateKey(tc.Key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+// writeTestPKCS12 writes the certificate, its key and chain as a PKCS#12 bundle
+func writeTestPKCS12(t *testing.T, path, password string, tc *testCert, chain ...*testCert) {
+	t.Helper()
+
+	var caCerts []*x509.Certificate
+	for _, c := range chain {
+		caCerts = append(caCerts, c.Cert)
+	}
+
+	data, err := pkcs12.Modern.Encode(tc.Key, tc.Cert, caCerts, password)
+	if err != nil {
+		t.Fatalf("Failed to encode PKCS#12: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write PKCS#12: %v", err)
+	}
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	root := newTestCA(t, "Client Root", nil)
+	client := newTestClientCert(t, root)
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, client)
+	p12File := filepath.Join(dir, "client.p12")
+	writeTestPKCS12(t, p12File, "secret", client, root)
+
+	tests := []struct {
+		name       string
+		spec       ClientCertSpec
+		wantChain  int
+		wantErr    bool
+		wantErrMsg string
+	}{
+		{
+			name:      "PEM pair",
+			spec:      ClientCertSpec{CertFile: certFile, KeyFile: keyFile},
+			wantChain: 1,
+		},
+		{
+			name:      "PKCS#12 bundle",
+			spec:      ClientCertSpec{PKCS12File: p12File, PKCS12Password: "secret"},
+			wantChain: 2,
+		},
+		{
+			name:       "PKCS#12 wrong password",
+			spec:       ClientCertSpec{PKCS12File: p12File, PKCS12Password: "wrong"},
+			wantErr:    true,
+			wantErrMsg: "cannot decode PKCS#12 file",
+		},
+		{
+			name:       "certificate without key",
+			spec:       ClientCertSpec{CertFile: certFile},
+			wantErr:    true,
+			wantErrMsg: "must be specified together",
+		},
+		{
+			name:       "PKCS#12 and PEM",
+			spec:       ClientCertSpec{CertFile: certFile, KeyFile: keyFile, PKCS12File: p12File},
+			wantErr:    true,
+			wantErrMsg: "mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate, err := LoadClientCertificate(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadClientCertificate() expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Errorf("LoadClientCertificate() error = %v, want containing %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadClientCertificate() unexpected error: %v", err)
+			}
+
+			if len(certificate.Certificate) != tt.wantChain {
+				t.Errorf("LoadClientCertificate() chain length = %d, want %d", len(certificate.Certificate), tt.wantChain)
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_ClientAuth(t *testing.T) {
+	serverRoot := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, serverRoot, "example.com")
+	clientRoot := newTestCA(t, "Client Root", nil)
+	client := newTestClientCert(t, clientRoot)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientRoot.Cert)
+
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir(), client)
+
+	tests := []struct {
+		name          string
+		clientAuth    tls.ClientAuthType
+		maxVersion    uint16
+		globalCert    bool
+		hostCert      ClientCertSpec
+		wantNil       bool
+		wantSent      bool
+		wantErr       bool
+		wantErrSubstr string
+	}{
+		{
+			name:       "server does not ask",
+			clientAuth: tls.NoClientCert,
+			wantNil:    true,
+		},
+		{
+			name:       "global client certificate",
+			clientAuth: tls.RequireAndVerifyClientCert,
+			globalCert: true,
+			wantSent:   true,
+		},
+		{
+			name:       "per-host client certificate",
+			clientAuth: tls.RequireAndVerifyClientCert,
+			hostCert:   ClientCertSpec{CertFile: certFile, KeyFile: keyFile},
+			wantSent:   true,
+		},
+		{
+			name:       "optional client certificate not configured",
+			clientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:          "required over TLS 1.2 without certificate",
+			clientAuth:    tls.RequireAndVerifyClientCert,
+			maxVersion:    tls.VersionTLS12,
+			wantErr:       true,
+			wantErrSubstr: "server requested a client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := startTestTLSServer(t, &tls.Config{
+				Certificates: []tls.Certificate{leaf.tlsCertificate()},
+				ClientAuth:   tt.clientAuth,
+				ClientCAs:    clientCAs,
+				MaxVersion:   tt.maxVersion,
+			})
+
+			opts := Options{Timeout: 5 * time.Second, Insecure: true, Thresholds: DefaultThresholds()}
+			if tt.globalCert {
+				certificate := client.tlsCertificate()
+				opts.ClientCertificate = &certificate
+			}
+			checker := NewWithOptions(opts)
+
+			target := newTestTarget(t, host, port)
+			target.ClientCert = tt.hostCert
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("getCertInfoByHost() expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.wantErrSubstr) {
+					t.Errorf("getCertInfoByHost() error = %v, want containing %q", err, tt.wantErrSubstr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if tt.wantNil {
+				if info.ClientAuth != nil {
+					t.Errorf("getCertInfoByHost() ClientAuth = %+v, want nil", info.ClientAuth)
+				}
+				return
+			}
+
+			if info.ClientAuth == nil || !info.ClientAuth.Requested {
+				t.Fatalf("getCertInfoByHost() ClientAuth = %+v, want a recorded request", info.ClientAuth)
+			}
+
+			if info.ClientAuth.Sent != tt.wantSent {
+				t.Errorf("ClientAuth.Sent = %v, want %v", info.ClientAuth.Sent, tt.wantSent)
+			}
+
+			if len(info.ClientAuth.AcceptableCAs) != 1 || info.ClientAuth.AcceptableCAs[0] != "CN=Client Root" {
+				t.Errorf("ClientAuth.AcceptableCAs = %v, want [CN=Client Root]", info.ClientAuth.AcceptableCAs)
+			}
+		})
+	}
+}
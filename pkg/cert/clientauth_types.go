@@ -0,0 +1,21 @@
+package cert
+
+// ClientCertSpec locates a client certificate for mutual TLS, either as a
+// PEM certificate and key pair or as a PKCS#12 bundle
+type ClientCertSpec struct {
+	CertFile string
+	KeyFile  string
+	// PKCS12File is a .p12/.pfx bundle holding the certificate, its chain and key
+	PKCS12File     string
+	PKCS12Password string
+}
+
+// ClientAuthInfo reports the server's request for a client certificate
+type ClientAuthInfo struct {
+	// Requested is set when the server sent a CertificateRequest
+	Requested bool `json:"requested" yaml:"requested"`
+	// AcceptableCAs are the distinguished names the server advertised as acceptable issuers
+	AcceptableCAs []string `json:"acceptable_cas,omitempty" yaml:"acceptable_cas,omitempty"`
+	// Sent is set when a client certificate was presented
+	Sent bool `json:"sent" yaml:"sent"`
+}
@@ -0,0 +1,29 @@
+package cert
+
+import (
+	"crypto/tls"
+	"math"
+	"time"
+)
+
+// alpnProtocols are offered to HTTPS targets so the selected protocol can be reported
+var alpnProtocols = []string{"h2", "http/1.1"}
+
+// newConnectionInfo summarizes a completed handshake
+func newConnectionInfo(state *tls.ConnectionState, remoteAddr, proxy string, connect, handshake time.Duration) *ConnectionInfo {
+	return &ConnectionInfo{
+		TLSVersion:      tls.VersionName(state.Version),
+		CipherSuite:     tls.CipherSuiteName(state.CipherSuite),
+		ALPN:            state.NegotiatedProtocol,
+		OCSPStapled:     len(state.OCSPResponse) > 0,
+		ConnectMillis:   durationMillis(connect),
+		HandshakeMillis: durationMillis(handshake),
+		RemoteAddr:      remoteAddr,
+		Proxy:           proxy,
+	}
+}
+
+// durationMillis converts d to milliseconds rounded to two decimals
+func durationMillis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetCertInfoByHost_Connection(t *testing.T) {
+	t.Setenv("NO_PROXY", "")
+
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+	certificate := leaf.tlsCertificate()
+	certificate.OCSPStaple = []byte{0x30, 0x00}
+
+	proxyAddr, _ := startTestHTTPProxy(t, "")
+
+	tests := []struct {
+		name        string
+		server      *tls.Config
+		proxy       string
+		wantVersion string
+		wantALPN    string
+		wantStapled bool
+	}{
+		{
+			name:        "TLS 1.3 with ALPN",
+			server:      &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}, NextProtos: []string{"h2"}},
+			wantVersion: "TLS 1.3",
+			wantALPN:    "h2",
+		},
+		{
+			name:        "TLS 1.2 with staple, no ALPN",
+			server:      &tls.Config{Certificates: []tls.Certificate{certificate}, MaxVersion: tls.VersionTLS12},
+			wantVersion: "TLS 1.2",
+			wantStapled: true,
+		},
+		{
+			name:        "through a proxy",
+			server:      &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}},
+			proxy:       "http://" + proxyAddr,
+			wantVersion: "TLS 1.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := startTestTLSServer(t, tt.server)
+
+			opts := Options{Timeout: 5 * time.Second, Insecure: true, Thresholds: DefaultThresholds()}
+			if tt.proxy != "" {
+				proxyURL, err := ParseProxyURL(tt.proxy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.Proxy = proxyURL
+			}
+			checker := NewWithOptions(opts)
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			connection := info.Connection
+			if connection == nil {
+				t.Fatal("getCertInfoByHost() Connection is nil")
+			}
+
+			if connection.TLSVersion != tt.wantVersion {
+				t.Errorf("Connection.TLSVersion = %q, want %q", connection.TLSVersion, tt.wantVersion)
+			}
+
+			if connection.CipherSuite == "" {
+				t.Error("Connection.CipherSuite should be set")
+			}
+
+			if connection.ALPN != tt.wantALPN {
+				t.Errorf("Connection.ALPN = %q, want %q", connection.ALPN, tt.wantALPN)
+			}
+
+			if connection.OCSPStapled != tt.wantStapled {
+				t.Errorf("Connection.OCSPStapled = %v, want %v", connection.OCSPStapled, tt.wantStapled)
+			}
+
+			if connection.ConnectMillis < 0 || connection.HandshakeMillis <= 0 {
+				t.Errorf("Connection latencies = %v/%v ms, want positive values", connection.ConnectMillis, connection.HandshakeMillis)
+			}
+
+			wantRemote := net.JoinHostPort(host, strconv.Itoa(port))
+			wantProxy := ""
+			if tt.proxy != "" {
+				wantRemote = proxyAddr
+				wantProxy = tt.proxy
+			}
+			if connection.RemoteAddr != wantRemote {
+				t.Errorf("Connection.RemoteAddr = %q, want %q", connection.RemoteAddr, wantRemote)
+			}
+			if connection.Proxy != wantProxy {
+				t.Errorf("Connection.Proxy = %q, want %q", connection.Proxy, wantProxy)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig_ALPNOnlyForHTTPS(t *testing.T) {
+	checker := NewWithOptions(Options{Timeout: time.Second})
+
+	for _, tt := range []struct {
+		host     string
+		wantALPN bool
+	}{
+		{host: "example.com", wantALPN: true},
+		{host: "smtp://mail.example.com", wantALPN: false},
+		{host: "postgres://db.example.com", wantALPN: false},
+	} {
+		target, err := parseTarget(tt.host, "")
+		if err != nil {
+			t.Fatalf("parseTarget(%q) unexpected error: %v", tt.host, err)
+		}
+
+		tlsConfig := checker.newTLSConfig(target, nil, &ClientAuthInfo{})
+		if got := len(tlsConfig.NextProtos) > 0; got != tt.wantALPN {
+			t.Errorf("newTLSConfig(%q) offers ALPN = %v, want %v", tt.host, got, tt.wantALPN)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package cert
+
+// ConnectionInfo describes the connection a certificate was retrieved over
+type ConnectionInfo struct {
+	TLSVersion  string `json:"tls_version" yaml:"tls_version"`
+	CipherSuite string `json:"cipher_suite" yaml:"cipher_suite"`
+	// ALPN is the application protocol the server selected, if any
+	ALPN        string `json:"alpn,omitempty" yaml:"alpn,omitempty"`
+	OCSPStapled bool   `json:"ocsp_stapled" yaml:"ocsp_stapled"`
+	// ConnectMillis covers the TCP connection, including any proxy tunnel
+	ConnectMillis float64 `json:"connect_ms" yaml:"connect_ms"`
+	// HandshakeMillis covers the TLS handshake only, after any STARTTLS negotiation
+	HandshakeMillis float64 `json:"handshake_ms" yaml:"handshake_ms"`
+	// RemoteAddr is the address actually dialed; the proxy's when Proxy is set
+	RemoteAddr string `json:"remote_addr" yaml:"remote_addr"`
+	Proxy      string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+}
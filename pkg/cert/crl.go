@@ -0,0 +1,287 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCRLBytes bounds the size of a downloaded CRL
+	maxCRLBytes = 64 * 1024 * 1024
+
+	crlCacheFileMode = 0o644
+	crlCacheDirMode  = 0o755
+)
+
+// DefaultCRLCacheDir returns the directory used to cache downloaded CRLs
+func DefaultCRLCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "ssl-certs-checker", "crl")
+}
+
+// newCRLStore creates a store reading the given local files and caching downloads in cacheDir
+func newCRLStore(cacheDir string, files []string) *crlStore {
+	if cacheDir == "" {
+		cacheDir = DefaultCRLCacheDir()
+	}
+
+	return &crlStore{
+		cacheDir: cacheDir,
+		files:    files,
+		remote:   make(map[string]*x509.RevocationList),
+		fetching: make(map[string]*sync.Mutex),
+	}
+}
+
+// checkCRL looks the leaf up in the local CRL files and, when enabled, in the
+// CRLs published at its distribution points
+func (c *Checker) checkCRL(ctx context.Context, info *CertificateInfo, leaf *x509.Certificate, certs []*x509.Certificate) {
+	if !c.crl && len(c.crls.files) == 0 {
+		return
+	}
+
+	result := &CRLInfo{}
+	info.CRL = result
+
+	// A CRL whose signature cannot be checked could mark the leaf revoked or clean at will
+	issuer := findIssuer(leaf, certs)
+	if issuer == nil {
+		result.Error = "issuer not presented, CRL signature not verified"
+		return
+	}
+
+	now := time.Now()
+
+	var lists []namedCRL
+	var errs []string
+
+	local, err := c.crls.localLists()
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, candidate := range local {
+		if bytes.Equal(candidate.list.RawIssuer, leaf.RawIssuer) {
+			lists = append(lists, candidate)
+		}
+	}
+
+	if c.crl {
+		if len(leaf.CRLDistributionPoints) == 0 && len(lists) == 0 {
+			errs = append(errs, "certificate does not list a CRL distribution point")
+		}
+
+		for _, url := range leaf.CRLDistributionPoints {
+			list, err := c.crls.fetch(ctx, c.httpClient, url, now)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("CRL %s: %v", url, err))
+				continue
+			}
+			lists = append(lists, namedCRL{source: url, list: list})
+		}
+	}
+
+	if len(lists) == 0 && len(errs) == 0 {
+		errs = append(errs, "no CRL matches the certificate issuer")
+	}
+
+	for _, candidate := range lists {
+		if err := candidate.list.CheckSignatureFrom(issuer); err != nil {
+			errs = append(errs, fmt.Sprintf("CRL %s: invalid signature: %v", candidate.source, err))
+			continue
+		}
+
+		result.Source = candidate.source
+		result.ThisUpdate = candidate.list.ThisUpdate
+		result.NextUpdate = candidate.list.NextUpdate
+
+		for _, entry := range candidate.list.RevokedCertificateEntries {
+			if entry.SerialNumber != nil && entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+				result.Revoked = true
+				result.RevokedAt = entry.RevocationTime
+				break
+			}
+		}
+
+		if result.Revoked {
+			break
+		}
+	}
+
+	if result.Source == "" {
+		result.Error = strings.Join(errs, "; ")
+	} else if !result.NextUpdate.IsZero() && now.After(result.NextUpdate) {
+		result.Error = fmt.Sprintf("CRL is stale (next update was %s)", result.NextUpdate.Format(time.RFC3339))
+	}
+
+	if result.Revoked {
+		info.Status = info.Status.Worse(StatusRevoked)
+	}
+}
+
+// localLists parses the configured CRL files once
+func (s *crlStore) localLists() ([]namedCRL, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.loaded {
+		return s.local, s.localErr
+	}
+	s.loaded = true
+
+	var errs []string
+	for _, path := range s.files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("cannot read CRL file: %v", err))
+			continue
+		}
+
+		list, err := parseCRL(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid CRL file %s: %v", path, err))
+			continue
+		}
+
+		s.local = append(s.local, namedCRL{source: path, list: list})
+	}
+
+	if len(errs) > 0 {
+		s.localErr = fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return s.local, s.localErr
+}
+
+// fetch returns the CRL published at url, from memory, the disk cache, or the network.
+// Cached CRLs are reused until their NextUpdate has passed.
+// Concurrent fetches of the same URL wait for a single download.
+func (s *crlStore) fetch(ctx context.Context, client *http.Client, url string, now time.Time) (*x509.RevocationList, error) {
+	lock := s.urlLock(url)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if list, ok := s.cached(url); ok && isFresh(list, now) {
+		return list, nil
+	}
+
+	cachePath := filepath.Join(s.cacheDir, crlCacheKey(url)+".crl")
+	if data, err := os.ReadFile(cachePath); err == nil {
+		if list, err := parseCRL(data); err == nil && isFresh(list, now) {
+			s.store(url, list)
+			return list, nil
+		}
+	}
+
+	data, err := downloadCRL(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := parseCRL(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(url, list)
+
+	// The cache is best effort; a read-only cache directory must not fail the check
+	if err := os.MkdirAll(s.cacheDir, crlCacheDirMode); err == nil {
+		_ = os.WriteFile(cachePath, data, crlCacheFileMode)
+	}
+
+	return list, nil
+}
+
+// urlLock returns the lock serializing the fetches of url
+func (s *crlStore) urlLock(url string) *sync.Mutex {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lock, ok := s.fetching[url]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.fetching[url] = lock
+	}
+
+	return lock
+}
+
+// cached returns the CRL of url held in memory
+func (s *crlStore) cached(url string) (*x509.RevocationList, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list, ok := s.remote[url]
+	return list, ok
+}
+
+// store keeps the CRL of url in memory
+func (s *crlStore) store(url string, list *x509.RevocationList) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remote[url] = list
+}
+
+// downloadCRL retrieves a CRL over HTTP
+func downloadCRL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("unsupported distribution point scheme")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxCRLBytes))
+}
+
+// parseCRL parses a DER or PEM encoded CRL
+func parseCRL(data []byte) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, fmt.Errorf("unexpected PEM block type: %s", block.Type)
+		}
+		data = block.Bytes
+	}
+
+	return x509.ParseRevocationList(data)
+}
+
+// isFresh reports whether the CRL can still be used at now
+func isFresh(list *x509.RevocationList, now time.Time) bool {
+	return list.NextUpdate.IsZero() || now.Before(list.NextUpdate)
+}
+
+// crlCacheKey derives a file name from a distribution point URL
+func crlCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,326 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestCRL signs a CRL listing the given serials with the issuer's key
+func newTestCRL(t *testing.T, issuer *testCert, nextUpdate time.Time, revoked ...*big.Int) []byte {
+	t.Helper()
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second),
+		})
+	}
+
+	der, err := x509.CreateRevocationList(nil, template, issuer.Cert, issuer.Key)
+	if err != nil {
+		t.Fatalf("Failed to create CRL: %v", err)
+	}
+
+	return der
+}
+
+// startTestCRLServer serves crl and counts the downloads
+func startTestCRLServer(t *testing.T, crl []byte) (string, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/pkix-crl")
+		w.Write(crl)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/intermediate.crl", &hits
+}
+
+func TestCheckCRL(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+
+	tests := []struct {
+		name        string
+		revoked     bool
+		nextUpdate  time.Duration
+		wantRevoked bool
+		wantStatus  Status
+		wantError   bool
+	}{
+		{
+			name:       "not revoked",
+			nextUpdate: 24 * time.Hour,
+			wantStatus: StatusOK,
+		},
+		{
+			name:        "revoked",
+			revoked:     true,
+			nextUpdate:  24 * time.Hour,
+			wantRevoked: true,
+			wantStatus:  StatusRevoked,
+		},
+		{
+			name:       "stale CRL",
+			nextUpdate: -time.Minute,
+			wantStatus: StatusOK,
+			wantError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serial := big.NewInt(time.Now().UnixNano())
+			var revoked []*big.Int
+			if tt.revoked {
+				revoked = append(revoked, serial)
+			}
+			crlURL, _ := startTestCRLServer(t, newTestCRL(t, intermediate, time.Now().Add(tt.nextUpdate), revoked...))
+
+			leaf := issueTestCert(t, &x509.Certificate{
+				SerialNumber:          serial,
+				Subject:               pkix.Name{CommonName: "example.com"},
+				DNSNames:              []string{"example.com"},
+				CRLDistributionPoints: []string{crlURL},
+				ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}, intermediate)
+
+			host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate(intermediate)}})
+
+			checker := NewWithOptions(Options{
+				Timeout:     5 * time.Second,
+				Insecure:    true,
+				Thresholds:  DefaultThresholds(),
+				CRL:         true,
+				CRLCacheDir: t.TempDir(),
+			})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.CRL == nil {
+				t.Fatal("getCertInfoByHost() CRL is nil")
+			}
+
+			if info.CRL.Source != crlURL {
+				t.Errorf("CRL.Source = %q, want %q (error: %s)", info.CRL.Source, crlURL, info.CRL.Error)
+			}
+
+			if info.CRL.Revoked != tt.wantRevoked {
+				t.Errorf("CRL.Revoked = %v, want %v", info.CRL.Revoked, tt.wantRevoked)
+			}
+
+			if tt.wantRevoked && info.CRL.RevokedAt.IsZero() {
+				t.Error("CRL.RevokedAt should be set for revoked certificates")
+			}
+
+			if (info.CRL.Error != "") != tt.wantError {
+				t.Errorf("CRL.Error = %q, wantError %v", info.CRL.Error, tt.wantError)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckCRL_DiskCache(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	crlURL, hits := startTestCRLServer(t, newTestCRL(t, intermediate, time.Now().Add(24*time.Hour)))
+
+	leaf := issueTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "example.com"},
+		CRLDistributionPoints: []string{crlURL},
+	}, intermediate)
+	certs := []*x509.Certificate{leaf.Cert, intermediate.Cert}
+	cacheDir := t.TempDir()
+
+	for i := range 2 {
+		// A fresh checker has an empty memory cache, so the second run must be served from disk
+		checker := NewWithOptions(Options{Timeout: 5 * time.Second, CRL: true, CRLCacheDir: cacheDir})
+		info := &CertificateInfo{Status: StatusOK}
+		checker.checkCRL(context.Background(), info, leaf.Cert, certs)
+
+		if info.CRL == nil || info.CRL.Error != "" {
+			t.Fatalf("run %d: checkCRL() = %+v, want a clean result", i, info.CRL)
+		}
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("CRL downloaded %d times, want 1", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, crlCacheKey(crlURL)+".crl")); err != nil {
+		t.Errorf("CRL cache file missing: %v", err)
+	}
+}
+
+func TestCheckCRL_LocalFile(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	other := newTestCA(t, "Other CA", root)
+
+	leaf := newTestLeaf(t, intermediate, "example.com")
+	certs := []*x509.Certificate{leaf.Cert, intermediate.Cert}
+
+	dir := t.TempDir()
+	otherPath := filepath.Join(dir, "other.crl")
+	if err := os.WriteFile(otherPath, newTestCRL(t, other, time.Now().Add(time.Hour), leaf.Cert.SerialNumber), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pemPath := filepath.Join(dir, "intermediate.pem")
+	block := &pem.Block{Type: "X509 CRL", Bytes: newTestCRL(t, intermediate, time.Now().Add(time.Hour), leaf.Cert.SerialNumber)}
+	if err := os.WriteFile(pemPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewWithOptions(Options{Timeout: time.Second, CRLFiles: []string{otherPath, pemPath}})
+	info := &CertificateInfo{Status: StatusOK}
+	checker.checkCRL(context.Background(), info, leaf.Cert, certs)
+
+	if info.CRL == nil {
+		t.Fatal("checkCRL() CRL is nil")
+	}
+
+	if info.CRL.Source != pemPath {
+		t.Errorf("CRL.Source = %q, want %q (error: %s)", info.CRL.Source, pemPath, info.CRL.Error)
+	}
+
+	if !info.CRL.Revoked || info.Status != StatusRevoked {
+		t.Errorf("checkCRL() Revoked = %v, Status = %v, want revoked", info.CRL.Revoked, info.Status)
+	}
+}
+
+func TestCheckCRL_InvalidSignature(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	leaf := newTestLeaf(t, intermediate, "example.com")
+
+	// Same issuer name, different key
+	forged := newTestCA(t, "Test Intermediate", root)
+	path := filepath.Join(t.TempDir(), "forged.crl")
+	if err := os.WriteFile(path, newTestCRL(t, forged, time.Now().Add(time.Hour), leaf.Cert.SerialNumber), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewWithOptions(Options{Timeout: time.Second, CRLFiles: []string{path}})
+	info := &CertificateInfo{Status: StatusOK}
+	checker.checkCRL(context.Background(), info, leaf.Cert, []*x509.Certificate{leaf.Cert, intermediate.Cert})
+
+	if info.CRL == nil || info.CRL.Revoked || info.CRL.Error == "" {
+		t.Errorf("checkCRL() = %+v, want an unverified CRL to be rejected", info.CRL)
+	}
+
+	if info.Status != StatusOK {
+		t.Errorf("Status = %v, want %v", info.Status, StatusOK)
+	}
+}
+
+func TestCheckCRL_Disabled(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+
+	checker := NewWithOptions(Options{Timeout: time.Second})
+	info := &CertificateInfo{Status: StatusOK}
+	checker.checkCRL(context.Background(), info, leaf.Cert, []*x509.Certificate{leaf.Cert})
+
+	if info.CRL != nil {
+		t.Errorf("checkCRL() CRL = %+v, want nil when disabled", info.CRL)
+	}
+}
+
+func TestCRLStore_FetchConcurrent(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	crl := newTestCRL(t, root, time.Now().Add(time.Hour))
+
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var slowHits atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slowHits.Add(1)
+		entered <- struct{}{}
+		<-release
+		w.Write(crl)
+	}))
+	t.Cleanup(slow.Close)
+	fastURL, _ := startTestCRLServer(t, crl)
+
+	store := newCRLStore(t.TempDir(), nil)
+	client := &http.Client{Timeout: 5 * time.Second}
+	now := time.Now()
+
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := store.fetch(context.Background(), client, slow.URL+"/slow.crl", now)
+			errs <- err
+		}()
+	}
+	<-entered
+
+	// A download in progress must not hold back the other distribution points
+	if _, err := store.fetch(context.Background(), client, fastURL, now); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+
+	close(release)
+	for range 3 {
+		if err := <-errs; err != nil {
+			t.Errorf("fetch() unexpected error: %v", err)
+		}
+	}
+
+	if got := slowHits.Load(); got != 1 {
+		t.Errorf("CRL downloaded %d times, want 1", got)
+	}
+}
+
+func TestCheckCRL_IssuerNotPresented(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	leaf := newTestLeaf(t, intermediate, "example.com")
+
+	path := filepath.Join(t.TempDir(), "intermediate.crl")
+	if err := os.WriteFile(path, newTestCRL(t, intermediate, time.Now().Add(time.Hour), leaf.Cert.SerialNumber), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewWithOptions(Options{Timeout: time.Second, CRLFiles: []string{path}})
+	info := &CertificateInfo{Status: StatusOK}
+	checker.checkCRL(context.Background(), info, leaf.Cert, []*x509.Certificate{leaf.Cert})
+
+	if info.CRL == nil || info.CRL.Revoked || info.CRL.Source != "" {
+		t.Errorf("checkCRL() = %+v, want the unverifiable CRL to be skipped", info.CRL)
+	}
+
+	if info.CRL != nil && info.CRL.Error != "issuer not presented, CRL signature not verified" {
+		t.Errorf("CRL.Error = %q, want the missing issuer reported", info.CRL.Error)
+	}
+
+	if info.Status != StatusOK {
+		t.Errorf("Status = %v, want %v", info.Status, StatusOK)
+	}
+}
@@ -0,0 +1,38 @@
+package cert
+
+import (
+	"crypto/x509"
+	"sync"
+	"time"
+)
+
+// CRLInfo describes the CRL revocation status of a leaf certificate
+type CRLInfo struct {
+	// Source is the distribution point URL or local file the CRL came from
+	Source     string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Revoked    bool      `json:"revoked" yaml:"revoked"`
+	RevokedAt  time.Time `json:"revoked_at,omitzero" yaml:"revoked_at,omitempty"`
+	ThisUpdate time.Time `json:"this_update,omitzero" yaml:"this_update,omitempty"`
+	NextUpdate time.Time `json:"next_update,omitzero" yaml:"next_update,omitempty"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// crlStore loads CRLs from local files and distribution points, caching them in memory and on disk.
+// mutex guards the fields below it; each distribution point has its own lock in fetching so
+// that a slow download only holds back the checks waiting for the same URL.
+type crlStore struct {
+	mutex    sync.Mutex
+	cacheDir string
+	files    []string
+	loaded   bool
+	local    []namedCRL
+	localErr error
+	remote   map[string]*x509.RevocationList
+	fetching map[string]*sync.Mutex
+}
+
+// namedCRL is a parsed CRL together with where it came from
+type namedCRL struct {
+	source string
+	list   *x509.RevocationList
+}
@@ -0,0 +1,368 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	SCTSourceEmbedded SCTSource = "embedded"
+	SCTSourceTLS      SCTSource = "tls"
+	SCTSourceOCSP     SCTSource = "ocsp"
+
+	SCTStatusValid      SCTStatus = "valid"
+	SCTStatusInvalid    SCTStatus = "invalid"
+	SCTStatusUnknownLog SCTStatus = "unknown_log"
+	// SCTStatusNoIssuer marks embedded SCTs that cannot be verified because the issuer was not presented
+	SCTStatusNoIssuer SCTStatus = "no_issuer"
+)
+
+const (
+	sctVersionV1         = 0
+	sctHashSHA256        = 4
+	sctCertificateStamp  = 0
+	sctEntryX509         = 0
+	sctEntryPrecert      = 1
+	ctLongLifetimeCutoff = 180 * 24 * time.Hour
+)
+
+var (
+	oidSCTList     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	oidOCSPSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
+)
+
+// LoadCTLogList loads a log list in the JSON format published for browsers
+func LoadCTLogList(path string) (*CTLogList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CT log list: %w", err)
+	}
+
+	var file logListFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid CT log list: %w", err)
+	}
+
+	list := &CTLogList{logs: make(map[[sha256.Size]byte]CTLog)}
+	for _, operator := range file.Operators {
+		for _, entry := range append(operator.Logs, operator.TiledLogs...) {
+			der, err := base64.StdEncoding.DecodeString(entry.Key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key for CT log %q: %w", entry.Description, err)
+			}
+			key, err := x509.ParsePKIXPublicKey(der)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key for CT log %q: %w", entry.Description, err)
+			}
+
+			list.logs[sha256.Sum256(der)] = CTLog{Name: entry.Description, Operator: operator.Name, Key: key}
+		}
+	}
+
+	if len(list.logs) == 0 {
+		return nil, fmt.Errorf("no logs found in CT log list")
+	}
+
+	return list, nil
+}
+
+// checkSCTs records the SCTs delivered for the leaf in the certificate, the
+// TLS extension and a stapled OCSP response, verifies them when a log list is
+// configured and, with CT checking enabled, evaluates the browser CT policy
+func (c *Checker) checkSCTs(info *CertificateInfo, leaf *x509.Certificate, certs []*x509.Certificate, state *tls.ConnectionState) {
+	issuer := findIssuer(leaf, certs)
+	scts := collectSCTs(leaf, issuer, state)
+
+	for _, sct := range scts {
+		sctInfo := SCTInfo{
+			LogID:     base64.StdEncoding.EncodeToString(sct.logID[:]),
+			Timestamp: time.UnixMilli(int64(sct.timestamp)).UTC(),
+			Source:    sct.source,
+		}
+		if c.ctLogs != nil {
+			sctInfo.Status = c.ctLogs.verify(&sctInfo, sct, leaf, issuer)
+		}
+		info.SCTs = append(info.SCTs, sctInfo)
+	}
+
+	if c.ct {
+		info.CT = evaluateCTPolicy(info)
+	}
+}
+
+// collectSCTs parses the SCTs from every delivery method, ignoring malformed ones
+func collectSCTs(leaf, issuer *x509.Certificate, state *tls.ConnectionState) []signedCertificateTimestamp {
+	var scts []signedCertificateTimestamp
+
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(oidSCTList) {
+			scts = append(scts, parseSCTExtension(ext.Value, SCTSourceEmbedded)...)
+		}
+	}
+
+	if state != nil {
+		for _, raw := range state.SignedCertificateTimestamps {
+			if sct, err := parseSCT(raw); err == nil {
+				sct.source = SCTSourceTLS
+				scts = append(scts, sct)
+			}
+		}
+
+		if len(state.OCSPResponse) > 0 {
+			if resp, err := ocsp.ParseResponse(state.OCSPResponse, issuer); err == nil {
+				for _, ext := range resp.Extensions {
+					if ext.Id.Equal(oidOCSPSCTList) {
+						scts = append(scts, parseSCTExtension(ext.Value, SCTSourceOCSP)...)
+					}
+				}
+			}
+		}
+	}
+
+	return scts
+}
+
+// parseSCTExtension parses an SCT list wrapped in a DER OCTET STRING
+func parseSCTExtension(value []byte, source SCTSource) []signedCertificateTimestamp {
+	var list []byte
+	if rest, err := asn1.Unmarshal(value, &list); err != nil || len(rest) > 0 {
+		return nil
+	}
+
+	raws, err := parseSCTList(list)
+	if err != nil {
+		return nil
+	}
+
+	var scts []signedCertificateTimestamp
+	for _, raw := range raws {
+		if sct, err := parseSCT(raw); err == nil {
+			sct.source = source
+			scts = append(scts, sct)
+		}
+	}
+
+	return scts
+}
+
+// parseSCTList splits a TLS-encoded SignedCertificateTimestampList
+func parseSCTList(data []byte) ([][]byte, error) {
+	input := cryptobyte.String(data)
+	var list cryptobyte.String
+	if !input.ReadUint16LengthPrefixed(&list) || !input.Empty() {
+		return nil, fmt.Errorf("malformed SCT list")
+	}
+
+	var raws [][]byte
+	for !list.Empty() {
+		var raw cryptobyte.String
+		if !list.ReadUint16LengthPrefixed(&raw) {
+			return nil, fmt.Errorf("malformed SCT list")
+		}
+		raws = append(raws, raw)
+	}
+
+	return raws, nil
+}
+
+// parseSCT decodes a TLS-encoded v1 SignedCertificateTimestamp
+func parseSCT(raw []byte) (signedCertificateTimestamp, error) {
+	var sct signedCertificateTimestamp
+	var logID []byte
+	var extensions, signature cryptobyte.String
+	var sigAlg uint8
+
+	input := cryptobyte.String(raw)
+	if !input.ReadUint8(&sct.version) || sct.version != sctVersionV1 {
+		return sct, fmt.Errorf("unsupported SCT version")
+	}
+	if !input.ReadBytes(&logID, sha256.Size) ||
+		!input.ReadUint64(&sct.timestamp) ||
+		!input.ReadUint16LengthPrefixed(&extensions) ||
+		!input.ReadUint8(&sct.hashAlg) ||
+		!input.ReadUint8(&sigAlg) ||
+		!input.ReadUint16LengthPrefixed(&signature) ||
+		!input.Empty() {
+		return sct, fmt.Errorf("malformed SCT")
+	}
+
+	copy(sct.logID[:], logID)
+	sct.extensions = extensions
+	sct.signature = signature
+	return sct, nil
+}
+
+// verify checks the SCT signature against its log, naming the log on info
+func (l *CTLogList) verify(info *SCTInfo, sct signedCertificateTimestamp, leaf, issuer *x509.Certificate) SCTStatus {
+	log, ok := l.logs[sct.logID]
+	if !ok {
+		return SCTStatusUnknownLog
+	}
+	info.LogName = log.Name
+	info.Operator = log.Operator
+
+	var signed []byte
+	var err error
+	if sct.source == SCTSourceEmbedded {
+		if issuer == nil {
+			return SCTStatusNoIssuer
+		}
+		signed, err = sctSignedData(sct, leaf, issuer)
+	} else {
+		signed, err = sctSignedData(sct, leaf, nil)
+	}
+	if err != nil || sct.hashAlg != sctHashSHA256 {
+		return SCTStatusInvalid
+	}
+
+	digest := sha256.Sum256(signed)
+	switch key := log.Key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(key, digest[:], sct.signature) {
+			return SCTStatusValid
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sct.signature) == nil {
+			return SCTStatusValid
+		}
+	}
+
+	return SCTStatusInvalid
+}
+
+// sctSignedData rebuilds the data a log signed: the leaf itself for SCTs
+// delivered over TLS or OCSP, or the precertificate entry derived from the
+// leaf and its issuer for embedded SCTs
+func sctSignedData(sct signedCertificateTimestamp, leaf, issuer *x509.Certificate) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint8(sct.version)
+	b.AddUint8(sctCertificateStamp)
+	b.AddUint64(sct.timestamp)
+
+	if issuer == nil {
+		b.AddUint16(sctEntryX509)
+		b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(leaf.Raw)
+		})
+	} else {
+		tbs, err := precertTBS(leaf.RawTBSCertificate)
+		if err != nil {
+			return nil, err
+		}
+		issuerKeyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+
+		b.AddUint16(sctEntryPrecert)
+		b.AddBytes(issuerKeyHash[:])
+		b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(tbs)
+		})
+	}
+
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(sct.extensions)
+	})
+
+	return b.Bytes()
+}
+
+// precertTBS returns the TBSCertificate with the embedded SCT list removed,
+// which is what the log saw when it signed the precertificate
+func precertTBS(rawTBS []byte) ([]byte, error) {
+	input := cryptobyte.String(rawTBS)
+	var tbs cryptobyte.String
+	if !input.ReadASN1(&tbs, cbasn1.SEQUENCE) {
+		return nil, fmt.Errorf("malformed TBSCertificate")
+	}
+
+	extensionsTag := cbasn1.Tag(3).Constructed().ContextSpecific()
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		for !tbs.Empty() {
+			var element cryptobyte.String
+			var tag cbasn1.Tag
+			if !tbs.ReadAnyASN1Element(&element, &tag) {
+				b.SetError(fmt.Errorf("malformed TBSCertificate"))
+				return
+			}
+			if tag != extensionsTag {
+				b.AddBytes(element)
+				continue
+			}
+
+			var explicit, extensions cryptobyte.String
+			if !element.ReadASN1(&explicit, extensionsTag) || !explicit.ReadASN1(&extensions, cbasn1.SEQUENCE) {
+				b.SetError(fmt.Errorf("malformed extensions"))
+				return
+			}
+			b.AddASN1(extensionsTag, func(b *cryptobyte.Builder) {
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					for !extensions.Empty() {
+						var extension cryptobyte.String
+						if !extensions.ReadASN1Element(&extension, cbasn1.SEQUENCE) {
+							b.SetError(fmt.Errorf("malformed extension"))
+							return
+						}
+
+						body := extension
+						var fields cryptobyte.String
+						var id asn1.ObjectIdentifier
+						if !body.ReadASN1(&fields, cbasn1.SEQUENCE) || !fields.ReadASN1ObjectIdentifier(&id) {
+							b.SetError(fmt.Errorf("malformed extension"))
+							return
+						}
+						if !id.Equal(oidSCTList) {
+							b.AddBytes(extension)
+						}
+					}
+				})
+			})
+		}
+	})
+
+	return b.Bytes()
+}
+
+// evaluateCTPolicy applies the browser CT policy: embedded SCTs from 2 distinct
+// logs (3 for certificates valid longer than 180 days), or SCTs from 2 distinct
+// logs delivered over TLS or OCSP
+func evaluateCTPolicy(info *CertificateInfo) *CTPolicy {
+	embedded := make(map[string]bool)
+	delivered := make(map[string]bool)
+	for _, sct := range info.SCTs {
+		if sct.Status != "" && sct.Status != SCTStatusValid {
+			continue
+		}
+		if sct.Source == SCTSourceEmbedded {
+			embedded[sct.LogID] = true
+		} else {
+			delivered[sct.LogID] = true
+		}
+	}
+
+	policy := &CTPolicy{Required: 2, Qualified: len(embedded)}
+	if info.NotAfter.Sub(info.NotBefore) > ctLongLifetimeCutoff {
+		policy.Required = 3
+	}
+
+	if policy.Qualified < policy.Required && len(delivered) > 0 {
+		policy.Required, policy.Qualified = 2, len(delivered)
+	}
+	policy.Compliant = policy.Qualified >= policy.Required
+
+	return policy
+}
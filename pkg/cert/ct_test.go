@@ -0,0 +1,427 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/ocsp"
+)
+
+// testCTLog is a CT log that signs SCTs for tests
+type testCTLog struct {
+	name string
+	key  *ecdsa.PrivateKey
+	id   [sha256.Size]byte
+}
+
+func newTestCTLog(t *testing.T, name string) *testCTLog {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate log key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("Failed to marshal log key: %v", err)
+	}
+
+	return &testCTLog{name: name, key: key, id: sha256.Sum256(der)}
+}
+
+// sign returns a serialized SCT for the leaf, over the precertificate entry when issuer is set
+func (l *testCTLog) sign(t *testing.T, leaf, issuer *x509.Certificate, when time.Time) []byte {
+	t.Helper()
+
+	sct := signedCertificateTimestamp{logID: l.id, timestamp: uint64(when.UnixMilli()), hashAlg: sctHashSHA256}
+	signed, err := sctSignedData(sct, leaf, issuer)
+	if err != nil {
+		t.Fatalf("Failed to build SCT signed data: %v", err)
+	}
+	digest := sha256.Sum256(signed)
+	sct.signature, err = ecdsa.SignASN1(rand.Reader, l.key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign SCT: %v", err)
+	}
+
+	var b cryptobyte.Builder
+	b.AddUint8(sct.version)
+	b.AddBytes(sct.logID[:])
+	b.AddUint64(sct.timestamp)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {})
+	b.AddUint8(sct.hashAlg)
+	b.AddUint8(3)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(sct.signature)
+	})
+
+	return b.BytesOrPanic()
+}
+
+// marshalTestSCTList encodes SCTs as the DER OCTET STRING used in certificate and OCSP extensions
+func marshalTestSCTList(t *testing.T, scts ...[]byte) []byte {
+	t.Helper()
+
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, sct := range scts {
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(sct)
+			})
+		}
+	})
+
+	value, err := asn1.Marshal(b.BytesOrPanic())
+	if err != nil {
+		t.Fatalf("Failed to marshal SCT list: %v", err)
+	}
+
+	return value
+}
+
+// writeTestLogList writes a browser-style log list with the given logs
+func writeTestLogList(t *testing.T, logs ...*testCTLog) string {
+	t.Helper()
+
+	var entries []map[string]string
+	for _, log := range logs {
+		der, err := x509.MarshalPKIXPublicKey(log.key.Public())
+		if err != nil {
+			t.Fatalf("Failed to marshal log key: %v", err)
+		}
+		entries = append(entries, map[string]string{
+			"description": log.name,
+			"log_id":      base64.StdEncoding.EncodeToString(log.id[:]),
+			"key":         base64.StdEncoding.EncodeToString(der),
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"operators": []any{map[string]any{"name": "Test Operator", "logs": entries}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal log list: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "log_list.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write log list: %v", err)
+	}
+
+	return path
+}
+
+// newTestCTLeaf issues a leaf with SCTs from the given logs embedded, signed over its precertificate
+func newTestCTLeaf(t *testing.T, ca *testCert, logs ...*testCTLog) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	testSerial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(testSerial),
+		Subject:      pkix.Name{CommonName: "ct.example.com"},
+		DNSNames:     []string{"ct.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(0, 0, 90),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	create := func() *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.Key)
+		if err != nil {
+			t.Fatalf("Failed to create certificate: %v", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("Failed to parse certificate: %v", err)
+		}
+		return cert
+	}
+
+	precert := create()
+	var scts [][]byte
+	for _, log := range logs {
+		scts = append(scts, log.sign(t, precert, ca.Cert, time.Now()))
+	}
+	tmpl.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: marshalTestSCTList(t, scts...)}}
+
+	return &testCert{Cert: create(), Key: key}
+}
+
+func TestPrecertTBS(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	leaf := newTestCTLeaf(t, ca, newTestCTLog(t, "Log A"))
+
+	tbs, err := precertTBS(leaf.Cert.RawTBSCertificate)
+	if err != nil {
+		t.Fatalf("precertTBS() unexpected error: %v", err)
+	}
+
+	if bytes.Equal(tbs, leaf.Cert.RawTBSCertificate) {
+		t.Error("precertTBS() should remove the SCT list extension")
+	}
+	if bytes.Contains(tbs, marshalOID(t, oidSCTList)) {
+		t.Error("precertTBS() result still contains the SCT list OID")
+	}
+}
+
+func marshalOID(t *testing.T, oid asn1.ObjectIdentifier) []byte {
+	t.Helper()
+
+	der, err := asn1.Marshal(oid)
+	if err != nil {
+		t.Fatalf("Failed to marshal OID: %v", err)
+	}
+
+	return der
+}
+
+func TestCheckSCTs(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	logA := newTestCTLog(t, "Log A")
+	logB := newTestCTLog(t, "Log B")
+	logC := newTestCTLog(t, "Log C")
+	unknown := newTestCTLog(t, "Unknown Log")
+
+	leaf := newTestCTLeaf(t, ca, logA, unknown)
+	certs := []*x509.Certificate{leaf.Cert, ca.Cert}
+
+	tampered := logC.sign(t, leaf.Cert, nil, time.Now())
+	tampered[len(tampered)-1] ^= 0xFF
+
+	staple, err := ocsp.CreateResponse(ca.Cert, ca.Cert, ocsp.Response{
+		Status:          ocsp.Good,
+		SerialNumber:    leaf.Cert.SerialNumber,
+		ThisUpdate:      time.Now().Add(-time.Hour),
+		NextUpdate:      time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: oidOCSPSCTList, Value: marshalTestSCTList(t, logB.sign(t, leaf.Cert, nil, time.Now()))}},
+	}, ca.Key)
+	if err != nil {
+		t.Fatalf("Failed to create OCSP response: %v", err)
+	}
+
+	state := &tls.ConnectionState{
+		SignedCertificateTimestamps: [][]byte{logB.sign(t, leaf.Cert, nil, time.Now()), tampered, []byte("garbage")},
+		OCSPResponse:                staple,
+	}
+
+	logs, err := LoadCTLogList(writeTestLogList(t, logA, logB, logC))
+	if err != nil {
+		t.Fatalf("LoadCTLogList() unexpected error: %v", err)
+	}
+
+	t.Run("unverified", func(t *testing.T) {
+		checker := NewWithOptions(Options{Timeout: 5 * time.Second})
+		info := newCertificateInfo("ct.example.com:443", leaf.Cert)
+		checker.checkSCTs(info, leaf.Cert, certs, state)
+
+		wantSources := []SCTSource{SCTSourceEmbedded, SCTSourceEmbedded, SCTSourceTLS, SCTSourceTLS, SCTSourceOCSP}
+		if len(info.SCTs) != len(wantSources) {
+			t.Fatalf("SCTs = %+v, want %d entries", info.SCTs, len(wantSources))
+		}
+		for i, sct := range info.SCTs {
+			if sct.Source != wantSources[i] {
+				t.Errorf("SCTs[%d].Source = %q, want %q", i, sct.Source, wantSources[i])
+			}
+			if sct.Status != "" || sct.LogName != "" {
+				t.Errorf("SCTs[%d] should not be verified without a log list: %+v", i, sct)
+			}
+		}
+		if info.SCTs[0].LogID != base64.StdEncoding.EncodeToString(logA.id[:]) {
+			t.Errorf("SCTs[0].LogID = %q, want log A", info.SCTs[0].LogID)
+		}
+		if info.CT != nil {
+			t.Errorf("CT = %+v, want nil without CT checking", info.CT)
+		}
+	})
+
+	t.Run("verified", func(t *testing.T) {
+		checker := NewWithOptions(Options{Timeout: 5 * time.Second, CTLogs: logs})
+		info := newCertificateInfo("ct.example.com:443", leaf.Cert)
+		checker.checkSCTs(info, leaf.Cert, certs, state)
+
+		want := []struct {
+			status SCTStatus
+			log    string
+		}{
+			{SCTStatusValid, "Log A"},
+			{SCTStatusUnknownLog, ""},
+			{SCTStatusValid, "Log B"},
+			{SCTStatusInvalid, "Log C"},
+			{SCTStatusValid, "Log B"},
+		}
+		if len(info.SCTs) != len(want) {
+			t.Fatalf("SCTs = %+v, want %d entries", info.SCTs, len(want))
+		}
+		for i, sct := range info.SCTs {
+			if sct.Status != want[i].status || sct.LogName != want[i].log {
+				t.Errorf("SCTs[%d] = %s from %q, want %s from %q", i, sct.Status, sct.LogName, want[i].status, want[i].log)
+			}
+		}
+
+		// One valid embedded SCT and one log delivering over TLS and OCSP
+		if info.CT == nil || info.CT.Compliant || info.CT.Qualified != 1 || info.CT.Required != 2 {
+			t.Errorf("CT = %+v, want 1 of 2 qualified and not compliant", info.CT)
+		}
+	})
+
+	t.Run("embedded SCT without issuer", func(t *testing.T) {
+		checker := NewWithOptions(Options{Timeout: 5 * time.Second, CTLogs: logs})
+		info := newCertificateInfo("ct.example.com:443", leaf.Cert)
+		checker.checkSCTs(info, leaf.Cert, []*x509.Certificate{leaf.Cert}, nil)
+
+		if len(info.SCTs) != 2 || info.SCTs[0].Status != SCTStatusNoIssuer {
+			t.Errorf("SCTs = %+v, want embedded SCTs marked %s", info.SCTs, SCTStatusNoIssuer)
+		}
+	})
+}
+
+func TestEvaluateCTPolicy(t *testing.T) {
+	now := time.Now()
+	sct := func(log string, source SCTSource, status SCTStatus) SCTInfo {
+		return SCTInfo{LogID: log, Source: source, Status: status}
+	}
+
+	tests := []struct {
+		name          string
+		lifetime      time.Duration
+		scts          []SCTInfo
+		wantRequired  int
+		wantQualified int
+		wantCompliant bool
+	}{
+		{
+			name:          "two embedded, short lifetime",
+			lifetime:      90 * 24 * time.Hour,
+			scts:          []SCTInfo{sct("a", SCTSourceEmbedded, ""), sct("b", SCTSourceEmbedded, "")},
+			wantRequired:  2,
+			wantQualified: 2,
+			wantCompliant: true,
+		},
+		{
+			name:          "two embedded, long lifetime",
+			lifetime:      365 * 24 * time.Hour,
+			scts:          []SCTInfo{sct("a", SCTSourceEmbedded, ""), sct("b", SCTSourceEmbedded, "")},
+			wantRequired:  3,
+			wantQualified: 2,
+		},
+		{
+			name:          "same log twice",
+			lifetime:      90 * 24 * time.Hour,
+			scts:          []SCTInfo{sct("a", SCTSourceEmbedded, ""), sct("a", SCTSourceEmbedded, "")},
+			wantRequired:  2,
+			wantQualified: 1,
+		},
+		{
+			name:          "invalid SCTs do not count",
+			lifetime:      90 * 24 * time.Hour,
+			scts:          []SCTInfo{sct("a", SCTSourceEmbedded, SCTStatusValid), sct("b", SCTSourceEmbedded, SCTStatusInvalid)},
+			wantRequired:  2,
+			wantQualified: 1,
+		},
+		{
+			name:          "delivered over TLS and OCSP",
+			lifetime:      365 * 24 * time.Hour,
+			scts:          []SCTInfo{sct("a", SCTSourceTLS, SCTStatusValid), sct("b", SCTSourceOCSP, SCTStatusValid)},
+			wantRequired:  2,
+			wantQualified: 2,
+			wantCompliant: true,
+		},
+		{
+			name:         "no SCTs",
+			lifetime:     90 * 24 * time.Hour,
+			wantRequired: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &CertificateInfo{NotBefore: now, NotAfter: now.Add(tt.lifetime), SCTs: tt.scts}
+			got := evaluateCTPolicy(info)
+
+			if got.Required != tt.wantRequired || got.Qualified != tt.wantQualified || got.Compliant != tt.wantCompliant {
+				t.Errorf("evaluateCTPolicy() = %+v, want required %d, qualified %d, compliant %v", got, tt.wantRequired, tt.wantQualified, tt.wantCompliant)
+			}
+		})
+	}
+}
+
+func TestLoadCTLogList(t *testing.T) {
+	if _, err := LoadCTLogList(writeTestLogList(t, newTestCTLog(t, "Log A"))); err != nil {
+		t.Errorf("LoadCTLogList() unexpected error: %v", err)
+	}
+
+	invalid := map[string]string{
+		"not json":    "{",
+		"no logs":     `{"operators": []}`,
+		"invalid key": `{"operators": [{"name": "x", "logs": [{"description": "bad", "key": "AAAA"}]}]}`,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "log_list.json")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write log list: %v", err)
+			}
+			if _, err := LoadCTLogList(path); err == nil {
+				t.Error("LoadCTLogList() expected error but got none")
+			}
+		})
+	}
+
+	if _, err := LoadCTLogList(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCTLogList() should return error for a missing file")
+	}
+}
+
+func TestGetCertInfoByHost_SCTs(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	logA := newTestCTLog(t, "Log A")
+	logB := newTestCTLog(t, "Log B")
+	leaf := newTestCTLeaf(t, ca, logA, logB)
+
+	logs, err := LoadCTLogList(writeTestLogList(t, logA, logB))
+	if err != nil {
+		t.Fatalf("LoadCTLogList() unexpected error: %v", err)
+	}
+
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate(ca)}})
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true, Thresholds: DefaultThresholds(), CTLogs: logs})
+
+	info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+	if err != nil {
+		t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+	}
+
+	if len(info.SCTs) != 2 || info.SCTs[0].Status != SCTStatusValid || info.SCTs[1].Status != SCTStatusValid {
+		t.Errorf("SCTs = %+v, want two valid embedded SCTs", info.SCTs)
+	}
+	if info.CT == nil || !info.CT.Compliant {
+		t.Errorf("CT = %+v, want compliant", info.CT)
+	}
+	for _, finding := range info.Findings {
+		if finding.ID == RuleInsufficientSCTs {
+			t.Errorf("Findings should not contain %s: %+v", RuleInsufficientSCTs, finding)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"time"
+)
+
+// SCTSource is how a signed certificate timestamp was delivered
+type SCTSource string
+
+// SCTStatus is the outcome of verifying an SCT against the CT log list
+type SCTStatus string
+
+// SCTInfo is a signed certificate timestamp presented for the leaf
+type SCTInfo struct {
+	// LogID is the base64 SHA-256 of the log's public key
+	LogID     string    `json:"log_id" yaml:"log_id"`
+	LogName   string    `json:"log_name,omitempty" yaml:"log_name,omitempty"`
+	Operator  string    `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Source    SCTSource `json:"source" yaml:"source"`
+	// Status is only set when SCTs are verified against a log list
+	Status SCTStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// CTPolicy is the leaf's compliance with the browser CT policy
+type CTPolicy struct {
+	// Required is the number of SCTs from distinct logs the policy asks for
+	Required int `json:"required" yaml:"required"`
+	// Qualified counts distinct logs with a usable SCT: valid ones when
+	// verifying against a log list, otherwise every parsed SCT
+	Qualified int  `json:"qualified" yaml:"qualified"`
+	Compliant bool `json:"compliant" yaml:"compliant"`
+}
+
+// CTLog is a Certificate Transparency log from a log list
+type CTLog struct {
+	Name     string
+	Operator string
+	Key      crypto.PublicKey
+}
+
+// CTLogList holds the known CT logs, keyed by log ID
+type CTLogList struct {
+	logs map[[sha256.Size]byte]CTLog
+}
+
+// signedCertificateTimestamp is a parsed RFC 6962 SCT
+type signedCertificateTimestamp struct {
+	version    uint8
+	logID      [sha256.Size]byte
+	timestamp  uint64
+	extensions []byte
+	hashAlg    uint8
+	signature  []byte
+	source     SCTSource
+}
+
+// logListFile is the JSON log list published for browsers (v3 schema)
+type logListFile struct {
+	Operators []struct {
+		Name      string         `json:"name"`
+		Logs      []logListEntry `json:"logs"`
+		TiledLogs []logListEntry `json:"tiled_logs"`
+	} `json:"operators"`
+}
+
+type logListEntry struct {
+	Description string `json:"description"`
+	LogID       string `json:"log_id"`
+	Key         string `json:"key"`
+}
@@ -0,0 +1,149 @@
+package cert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	// postgresSSLRequestCode is the protocol code of the PostgreSQL SSLRequest message
+	postgresSSLRequestCode = 80877103
+
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	mysqlMaxPacketSize          = 1 << 24
+	mysqlCharsetUTF8MB4         = 45
+)
+
+func (postgresNegotiator) Name() string     { return "postgres" }
+func (postgresNegotiator) DefaultPort() int { return 5432 }
+
+func (postgresNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("sending SSLRequest: %w", err)
+	}
+
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("reading SSLRequest reply: %w", err)
+	}
+
+	switch reply[0] {
+	case 'S':
+		return nil
+	case 'N':
+		return fmt.Errorf("server does not support SSL")
+	default:
+		return fmt.Errorf("unexpected SSLRequest reply: %q", reply[0])
+	}
+}
+
+func (mysqlNegotiator) Name() string     { return "mysql" }
+func (mysqlNegotiator) DefaultPort() int { return 3306 }
+
+func (mysqlNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	payload, seq, err := readMySQLPacket(conn)
+	if err != nil {
+		return fmt.Errorf("reading initial handshake: %w", err)
+	}
+
+	capabilities, err := parseMySQLHandshake(payload)
+	if err != nil {
+		return err
+	}
+
+	if capabilities&mysqlClientSSL == 0 {
+		return fmt.Errorf("server does not support SSL")
+	}
+
+	request := make([]byte, 32)
+	binary.LittleEndian.PutUint32(request[0:4], mysqlClientLongPassword|mysqlClientProtocol41|mysqlClientSSL|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(request[4:8], mysqlMaxPacketSize)
+	request[8] = mysqlCharsetUTF8MB4
+
+	if err := writeMySQLPacket(conn, seq+1, request); err != nil {
+		return fmt.Errorf("sending SSL request: %w", err)
+	}
+
+	return nil
+}
+
+// parseMySQLHandshake returns the capability flags advertised in a protocol v10 handshake
+func parseMySQLHandshake(payload []byte) (uint32, error) {
+	if len(payload) == 0 {
+		return 0, fmt.Errorf("empty initial handshake")
+	}
+
+	if payload[0] == 0xff {
+		message := ""
+		if len(payload) > 3 {
+			message = string(payload[3:])
+		}
+		return 0, fmt.Errorf("server refused connection: %s", message)
+	}
+
+	if payload[0] != 10 {
+		return 0, fmt.Errorf("unsupported handshake protocol version: %d", payload[0])
+	}
+
+	// Skip the null-terminated server version
+	pos := 1
+	for pos < len(payload) && payload[pos] != 0 {
+		pos++
+	}
+	pos++
+
+	// connection id (4), auth-plugin-data part 1 (8), filler (1)
+	pos += 4 + 8 + 1
+	if pos+2 > len(payload) {
+		return 0, fmt.Errorf("truncated initial handshake")
+	}
+
+	capabilities := uint32(binary.LittleEndian.Uint16(payload[pos : pos+2]))
+	pos += 2
+
+	// character set (1), status flags (2), then the upper capability flags
+	pos += 1 + 2
+	if pos+2 <= len(payload) {
+		capabilities |= uint32(binary.LittleEndian.Uint16(payload[pos:pos+2])) << 16
+	}
+
+	return capabilities, nil
+}
+
+// readMySQLPacket reads one MySQL protocol packet and returns its payload and sequence id
+func readMySQLPacket(r io.Reader) ([]byte, byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length > maxNegotiationBytes {
+		return nil, 0, fmt.Errorf("packet too large: %d bytes", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+
+	return payload, header[3], nil
+}
+
+// writeMySQLPacket writes payload as a single MySQL protocol packet
+func writeMySQLPacket(w io.Writer, seq byte, payload []byte) error {
+	length := len(payload)
+	packet := append([]byte{byte(length), byte(length >> 8), byte(length >> 16), seq}, payload...)
+
+	_, err := w.Write(packet)
+	return err
+}
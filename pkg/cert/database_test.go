@@ -0,0 +1,142 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakePostgresServer(reply byte) func(conn net.Conn, r *bufio.Reader) error {
+	return func(conn net.Conn, r *bufio.Reader) error {
+		request := make([]byte, 8)
+		if _, err := io.ReadFull(r, request); err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint32(request[4:8]) != postgresSSLRequestCode {
+			return fmt.Errorf("unexpected request code")
+		}
+
+		conn.Write([]byte{reply})
+		if reply != 'S' {
+			return fmt.Errorf("ssl refused")
+		}
+		return nil
+	}
+}
+
+// mysqlHandshakePayload builds a protocol v10 initial handshake with the given capabilities
+func mysqlHandshakePayload(capabilities uint32) []byte {
+	payload := []byte{10}
+	payload = append(payload, []byte("8.0.36")...)
+	payload = append(payload, 0)
+	payload = append(payload, 1, 0, 0, 0)         // connection id
+	payload = append(payload, make([]byte, 8)...) // auth-plugin-data part 1
+	payload = append(payload, 0)                  // filler
+	payload = binary.LittleEndian.AppendUint16(payload, uint16(capabilities))
+	payload = append(payload, mysqlCharsetUTF8MB4)
+	payload = append(payload, 2, 0) // status flags
+	payload = binary.LittleEndian.AppendUint16(payload, uint16(capabilities>>16))
+	return payload
+}
+
+func fakeMySQLServer(capabilities uint32) func(conn net.Conn, r *bufio.Reader) error {
+	return func(conn net.Conn, r *bufio.Reader) error {
+		if err := writeMySQLPacket(conn, 0, mysqlHandshakePayload(capabilities)); err != nil {
+			return err
+		}
+
+		payload, seq, err := readMySQLPacket(r)
+		if err != nil {
+			return err
+		}
+		if seq != 1 || len(payload) != 32 {
+			return fmt.Errorf("unexpected SSL request packet")
+		}
+		if binary.LittleEndian.Uint32(payload[0:4])&mysqlClientSSL == 0 {
+			return fmt.Errorf("client did not request SSL")
+		}
+		return nil
+	}
+}
+
+func TestDatabaseNegotiators(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "db.example.com")
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}}
+
+	tests := []struct {
+		name    string
+		scheme  string
+		server  func(conn net.Conn, r *bufio.Reader) error
+		wantErr string
+	}{
+		{
+			name:   "postgres accepts SSL",
+			scheme: "postgres",
+			server: fakePostgresServer('S'),
+		},
+		{
+			name:    "postgres refuses SSL",
+			scheme:  "postgres",
+			server:  fakePostgresServer('N'),
+			wantErr: "server does not support SSL",
+		},
+		{
+			name:   "mysql advertises SSL",
+			scheme: "mysql",
+			server: fakeMySQLServer(mysqlClientProtocol41 | mysqlClientSSL | mysqlClientSecureConnection),
+		},
+		{
+			name:    "mysql without SSL capability",
+			scheme:  "mysql",
+			server:  fakeMySQLServer(mysqlClientProtocol41 | mysqlClientSecureConnection),
+			wantErr: "server does not support SSL",
+		},
+	}
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := startTestUpgradeServer(t, tlsConfig, tt.server)
+
+			target, err := parseTarget(fmt.Sprintf("%s://%s:%d", tt.scheme, host, port), "")
+			if err != nil {
+				t.Fatalf("parseTarget() unexpected error: %v", err)
+			}
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("getCertInfoByHost() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.CommonName != "db.example.com" {
+				t.Errorf("getCertInfoByHost() CommonName = %q, want db.example.com", info.CommonName)
+			}
+		})
+	}
+}
+
+func TestParseMySQLHandshake_ErrorPacket(t *testing.T) {
+	payload := append([]byte{0xff, 0x6a, 0x04}, []byte("Host is blocked")...)
+
+	_, err := parseMySQLHandshake(payload)
+	if err == nil || !strings.Contains(err.Error(), "Host is blocked") {
+		t.Errorf("parseMySQLHandshake() error = %v, want server refusal message", err)
+	}
+}
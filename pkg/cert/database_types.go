@@ -0,0 +1,7 @@
+package cert
+
+// postgresNegotiator upgrades PostgreSQL connections with an SSLRequest message
+type postgresNegotiator struct{}
+
+// mysqlNegotiator upgrades MySQL connections with an SSL request packet
+type mysqlNegotiator struct{}
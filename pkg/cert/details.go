@@ -0,0 +1,124 @@
+package cert
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:                            "Any",
+	x509.ExtKeyUsageServerAuth:                     "ServerAuth",
+	x509.ExtKeyUsageClientAuth:                     "ClientAuth",
+	x509.ExtKeyUsageCodeSigning:                    "CodeSigning",
+	x509.ExtKeyUsageEmailProtection:                "EmailProtection",
+	x509.ExtKeyUsageIPSECEndSystem:                 "IPSECEndSystem",
+	x509.ExtKeyUsageIPSECTunnel:                    "IPSECTunnel",
+	x509.ExtKeyUsageIPSECUser:                      "IPSECUser",
+	x509.ExtKeyUsageTimeStamping:                   "TimeStamping",
+	x509.ExtKeyUsageOCSPSigning:                    "OCSPSigning",
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto:     "MicrosoftServerGatedCrypto",
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:      "NetscapeServerGatedCrypto",
+	x509.ExtKeyUsageMicrosoftCommercialCodeSigning: "MicrosoftCommercialCodeSigning",
+	x509.ExtKeyUsageMicrosoftKernelCodeSigning:     "MicrosoftKernelCodeSigning",
+}
+
+// addCertificateDetails fills in the audit fields: fingerprints, key and
+// signature details, extended key usage, non-DNS SANs, policies and AIA/CRL URLs
+func addCertificateDetails(info *CertificateInfo, cert *x509.Certificate) {
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+
+	info.IssuerDN = cert.Issuer.String()
+	info.SHA1Fingerprint = colonHex(sha1Sum[:])
+	info.SHA256Fingerprint = colonHex(sha256Sum[:])
+	info.SPKISHA256 = spkiPin(cert)
+	info.SignatureAlgorithm = cert.SignatureAlgorithm.String()
+	info.KeySize, info.KeyCurve = keyDetails(cert)
+	info.ExtKeyUsage = extKeyUsageList(cert)
+
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	info.EmailAddresses = cert.EmailAddresses
+	for _, uri := range cert.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+
+	for _, policy := range cert.Policies {
+		info.PolicyOIDs = append(info.PolicyOIDs, policy.String())
+	}
+	info.OCSPServers = cert.OCSPServer
+	info.IssuingCertificateURLs = cert.IssuingCertificateURL
+	info.CRLDistributionPoints = cert.CRLDistributionPoints
+}
+
+// spkiPin returns the base64 SHA-256 of the SubjectPublicKeyInfo, as used for key pinning
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// keyDetails returns the public key size in bits and, for elliptic curve keys, the curve name
+func keyDetails(cert *x509.Certificate) (int, string) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen(), ""
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize, key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return 256, "Ed25519"
+	case *ecdh.PublicKey:
+		return len(key.Bytes()) * 8, fmt.Sprint(key.Curve())
+	default:
+		return 0, ""
+	}
+}
+
+// extKeyUsageList names the extended key usages, falling back to OIDs for unknown ones
+func extKeyUsageList(cert *x509.Certificate) []string {
+	var names []string
+	for _, usage := range cert.ExtKeyUsage {
+		if name, ok := extKeyUsageNames[usage]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, fmt.Sprintf("Unknown(%d)", usage))
+		}
+	}
+	for _, oid := range cert.UnknownExtKeyUsage {
+		names = append(names, oid.String())
+	}
+
+	return names
+}
+
+// formatSerial renders the serial number as colon-separated uppercase hex
+func formatSerial(cert *x509.Certificate) string {
+	if cert.SerialNumber == nil {
+		return ""
+	}
+
+	raw := cert.SerialNumber.Bytes()
+	if len(raw) == 0 {
+		return "00"
+	}
+
+	return colonHex(raw)
+}
+
+// colonHex renders bytes as colon-separated uppercase hex
+func colonHex(raw []byte) string {
+	parts := make([]string, len(raw))
+	for i, b := range raw {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,120 @@
+package cert
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewCertificateInfo_Details(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	uri, _ := url.Parse("spiffe://example.com/service")
+	policy, err := x509.OIDFromInts([]uint64{2, 23, 140, 1, 2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := issueTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "example.com", Organization: []string{"Example Org"}},
+		DNSNames:              []string{"example.com"},
+		IPAddresses:           []net.IP{net.ParseIP("192.0.2.10")},
+		EmailAddresses:        []string{"admin@example.com"},
+		URIs:                  []*url.URL{uri},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		UnknownExtKeyUsage:    []asn1.ObjectIdentifier{{1, 2, 3, 4}},
+		Policies:              []x509.OID{policy},
+		OCSPServer:            []string{"http://ocsp.example.com"},
+		IssuingCertificateURL: []string{"http://ca.example.com/root.crt"},
+		CRLDistributionPoints: []string{"http://crl.example.com/root.crl"},
+	}, root)
+
+	info := newCertificateInfo("example.com:443", leaf.Cert)
+
+	sum := sha256.Sum256(leaf.Cert.Raw)
+	if info.SHA256Fingerprint != colonHex(sum[:]) || len(info.SHA256Fingerprint) != 32*3-1 {
+		t.Errorf("SHA256Fingerprint = %q", info.SHA256Fingerprint)
+	}
+	if len(info.SHA1Fingerprint) != 20*3-1 {
+		t.Errorf("SHA1Fingerprint = %q, want 20 colon-separated bytes", info.SHA1Fingerprint)
+	}
+	if info.SPKISHA256 != spkiPin(leaf.Cert) || len(info.SPKISHA256) != 44 {
+		t.Errorf("SPKISHA256 = %q, want base64 SHA-256", info.SPKISHA256)
+	}
+
+	checks := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{"IssuerDN", info.IssuerDN, "CN=Test Root"},
+		{"SignatureAlgorithm", info.SignatureAlgorithm, "ECDSA-SHA256"},
+		{"KeySize", info.KeySize, 256},
+		{"KeyCurve", info.KeyCurve, "P-256"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+		}
+	}
+
+	lists := []struct {
+		field string
+		got   []string
+		want  []string
+	}{
+		{"ExtKeyUsage", info.ExtKeyUsage, []string{"ServerAuth", "ClientAuth", "1.2.3.4"}},
+		{"IPAddresses", info.IPAddresses, []string{"192.0.2.10"}},
+		{"EmailAddresses", info.EmailAddresses, []string{"admin@example.com"}},
+		{"URIs", info.URIs, []string{"spiffe://example.com/service"}},
+		{"PolicyOIDs", info.PolicyOIDs, []string{"2.23.140.1.2.1"}},
+		{"OCSPServers", info.OCSPServers, []string{"http://ocsp.example.com"}},
+		{"IssuingCertificateURLs", info.IssuingCertificateURLs, []string{"http://ca.example.com/root.crt"}},
+		{"CRLDistributionPoints", info.CRLDistributionPoints, []string{"http://crl.example.com/root.crl"}},
+	}
+	for _, list := range lists {
+		if !slices.Equal(list.got, list.want) {
+			t.Errorf("%s = %v, want %v", list.field, list.got, list.want)
+		}
+	}
+
+	if !strings.Contains(info.Subject, "O=Example Org") {
+		t.Errorf("Subject = %q, want the full DN", info.Subject)
+	}
+}
+
+func TestKeyDetails(t *testing.T) {
+	modulus := new(big.Int).Lsh(big.NewInt(1), 2047)
+	edKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		key       any
+		wantSize  int
+		wantCurve string
+	}{
+		{name: "RSA", key: &rsa.PublicKey{N: modulus, E: 65537}, wantSize: 2048},
+		{name: "Ed25519", key: edKey, wantSize: 256, wantCurve: "Ed25519"},
+		{name: "unknown", key: "not a key", wantSize: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, curve := keyDetails(&x509.Certificate{PublicKey: tt.key})
+			if size != tt.wantSize || curve != tt.wantCurve {
+				t.Errorf("keyDetails() = %d, %q, want %d, %q", size, curve, tt.wantSize, tt.wantCurve)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package cert
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeFailed  ChangeKind = "failed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// diffFields are the compared certificate fields, named as in --fields
+var diffFields = []struct {
+	name  string
+	value func(CertificateInfo) string
+}{
+	{"sha256_fingerprint", func(c CertificateInfo) string { return c.SHA256Fingerprint }},
+	{"serial_number", func(c CertificateInfo) string { return c.SerialNumber }},
+	{"common_name", func(c CertificateInfo) string { return c.CommonName }},
+	{"dns_names", func(c CertificateInfo) string { return strings.Join(c.DNSNames, ",") }},
+	{"issuer", func(c CertificateInfo) string { return c.Issuer }},
+	{"not_after", func(c CertificateInfo) string { return c.NotAfter.UTC().Format(time.RFC3339) }},
+	{"status", func(c CertificateInfo) string { return string(c.Status) }},
+	{"verified", func(c CertificateInfo) string { return strconv.FormatBool(c.Verified) }},
+}
+
+// DiffResults compares the certificates of two results by host. Certificates of
+// the baseline are listed first in their order, followed by the added ones.
+// A host that now fails to be checked is reported as failed rather than removed.
+func DiffResults(baseline, current *Result) *Diff {
+	diff := &Diff{Changes: make([]CertificateChange, 0)}
+
+	currentByHost := make(map[string]CertificateInfo, len(current.Certificates))
+	for _, info := range current.Certificates {
+		if _, ok := currentByHost[info.Host]; !ok {
+			currentByHost[info.Host] = info
+		}
+	}
+	errorsByHost := make(map[string]string, len(current.Errors))
+	for _, e := range current.Errors {
+		errorsByHost[e.Host] = e.Error
+	}
+
+	seen := make(map[string]bool, len(baseline.Certificates))
+	for _, old := range baseline.Certificates {
+		if seen[old.Host] {
+			continue
+		}
+		seen[old.Host] = true
+
+		info, ok := currentByHost[old.Host]
+		if !ok {
+			if reason, failed := errorsByHost[old.Host]; failed {
+				diff.Changes = append(diff.Changes, CertificateChange{Host: old.Host, Change: ChangeFailed, Error: reason})
+			} else {
+				diff.Changes = append(diff.Changes, CertificateChange{Host: old.Host, Change: ChangeRemoved})
+			}
+			continue
+		}
+
+		var fields []FieldChange
+		for _, field := range diffFields {
+			if before, after := field.value(old), field.value(info); before != after {
+				fields = append(fields, FieldChange{Field: field.name, Old: before, New: after})
+			}
+		}
+		if len(fields) > 0 {
+			diff.Changes = append(diff.Changes, CertificateChange{Host: old.Host, Change: ChangeChanged, Fields: fields})
+		}
+	}
+
+	for _, info := range current.Certificates {
+		if seen[info.Host] {
+			continue
+		}
+		seen[info.Host] = true
+		diff.Changes = append(diff.Changes, CertificateChange{Host: info.Host, Change: ChangeAdded})
+	}
+
+	return diff
+}
+
+// HasChanges reports whether any certificate differs
+func (d *Diff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
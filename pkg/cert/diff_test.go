@@ -0,0 +1,71 @@
+package cert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffResults(t *testing.T) {
+	notAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := func(host, fingerprint string, status Status) CertificateInfo {
+		return CertificateInfo{
+			Host:              host,
+			CommonName:        host,
+			DNSNames:          []string{host},
+			Issuer:            "Example CA",
+			NotAfter:          notAfter,
+			Status:            status,
+			Verified:          true,
+			SHA256Fingerprint: fingerprint,
+			SerialNumber:      "01",
+		}
+	}
+
+	renewed := info("renewed.example.com:443", "BB", StatusOK)
+	renewed.SerialNumber = "02"
+	renewed.NotAfter = notAfter.AddDate(0, 3, 0)
+
+	baseline := &Result{Certificates: []CertificateInfo{
+		info("same.example.com:443", "AA", StatusOK),
+		info("renewed.example.com:443", "AA", StatusWarning),
+		info("gone.example.com:443", "AA", StatusOK),
+		info("down.example.com:443", "AA", StatusOK),
+	}}
+	current := &Result{
+		Certificates: []CertificateInfo{
+			info("new.example.com:443", "CC", StatusOK),
+			renewed,
+			info("same.example.com:443", "AA", StatusOK),
+		},
+		Errors: []ErrorInfo{{Host: "down.example.com:443", Error: "connection refused"}},
+	}
+
+	want := []CertificateChange{
+		{
+			Host:   "renewed.example.com:443",
+			Change: ChangeChanged,
+			Fields: []FieldChange{
+				{Field: "sha256_fingerprint", Old: "AA", New: "BB"},
+				{Field: "serial_number", Old: "01", New: "02"},
+				{Field: "not_after", Old: "2026-01-01T00:00:00Z", New: "2026-04-01T00:00:00Z"},
+				{Field: "status", Old: "WARNING", New: "OK"},
+			},
+		},
+		{Host: "gone.example.com:443", Change: ChangeRemoved},
+		{Host: "down.example.com:443", Change: ChangeFailed, Error: "connection refused"},
+		{Host: "new.example.com:443", Change: ChangeAdded},
+	}
+
+	diff := DiffResults(baseline, current)
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("DiffResults() = %+v, want %+v", diff.Changes, want)
+	}
+	if !diff.HasChanges() {
+		t.Error("HasChanges() = false, want true")
+	}
+
+	if diff := DiffResults(baseline, baseline); diff.HasChanges() {
+		t.Errorf("DiffResults() of identical results = %+v, want no changes", diff.Changes)
+	}
+}
@@ -0,0 +1,25 @@
+package cert
+
+// ChangeKind tells how a certificate differs between two results
+type ChangeKind string
+
+// FieldChange is a certificate field whose value differs between two results
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+}
+
+// CertificateChange is a certificate that appeared, disappeared, failed or changed
+type CertificateChange struct {
+	Host   string        `json:"host" yaml:"host"`
+	Change ChangeKind    `json:"change" yaml:"change"`
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Error is the reason a previously reported certificate could not be checked
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Diff lists the certificate changes between a baseline result and a current one
+type Diff struct {
+	Changes []CertificateChange `json:"changes" yaml:"changes"`
+}
@@ -0,0 +1,168 @@
+package cert
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+const (
+	RuleWeakRSAKey        = "weak_rsa_key"
+	RuleWeakSignature     = "weak_signature"
+	RuleDeprecatedCurve   = "deprecated_curve"
+	RuleLongValidity      = "long_validity"
+	RuleMissingServerAuth = "missing_server_auth"
+	RuleInsufficientSCTs  = "insufficient_scts"
+)
+
+const (
+	MinRSAKeyBits       = 2048
+	MaxLeafValidityDays = 398
+)
+
+// approvedCurves are the elliptic curves still accepted for TLS certificates
+var approvedCurves = []string{"P-256", "P-384", "P-521", "Ed25519"}
+
+// weakSignatureAlgorithms are signature algorithms considered broken
+var weakSignatureAlgorithms = []string{"MD2", "MD5", "SHA1"}
+
+// DefaultRules returns the built-in weak cryptography rules
+func DefaultRules() []Rule {
+	return []Rule{
+		{ID: RuleWeakRSAKey, Severity: SeverityCritical, Check: checkWeakRSAKey},
+		{ID: RuleWeakSignature, Severity: SeverityCritical, Check: checkWeakSignature},
+		{ID: RuleDeprecatedCurve, Severity: SeverityWarning, Check: checkDeprecatedCurve},
+		{ID: RuleLongValidity, Severity: SeverityWarning, Check: checkLongValidity},
+		{ID: RuleMissingServerAuth, Severity: SeverityWarning, Check: checkMissingServerAuth},
+		{ID: RuleInsufficientSCTs, Severity: SeverityWarning, Check: checkInsufficientSCTs},
+	}
+}
+
+// WithoutRules returns rules minus the ones whose IDs are listed in disabled
+func WithoutRules(rules []Rule, disabled []string) ([]Rule, error) {
+	for _, id := range disabled {
+		if !slices.ContainsFunc(rules, func(rule Rule) bool { return rule.ID == id }) {
+			return nil, fmt.Errorf("unknown rule: %s", id)
+		}
+	}
+
+	selected := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if !slices.Contains(disabled, rule.ID) {
+			selected = append(selected, rule)
+		}
+	}
+
+	return selected, nil
+}
+
+// ApplyRules evaluates info against rules, records the findings and raises
+// the status to match the most severe one
+func ApplyRules(info *CertificateInfo, rules []Rule) {
+	for _, rule := range rules {
+		message, found := rule.Check(info)
+		if !found {
+			continue
+		}
+
+		info.Findings = append(info.Findings, Finding{ID: rule.ID, Severity: rule.Severity, Message: message})
+		info.Status = info.Status.Worse(rule.Severity.Status())
+	}
+}
+
+// ApplyRules evaluates every certificate of the result against rules
+func (r *Result) ApplyRules(rules []Rule) {
+	for i := range r.Certificates {
+		ApplyRules(&r.Certificates[i], rules)
+	}
+}
+
+// Status maps the severity to the certificate status it implies
+func (s Severity) Status() Status {
+	switch s {
+	case SeverityWarning:
+		return StatusWarning
+	case SeverityCritical:
+		return StatusCritical
+	default:
+		return StatusOK
+	}
+}
+
+// checkWeakRSAKey flags RSA keys shorter than MinRSAKeyBits
+func checkWeakRSAKey(info *CertificateInfo) (string, bool) {
+	if info.PublicKeyAlgorithm != "RSA" || info.KeySize == 0 || info.KeySize >= MinRSAKeyBits {
+		return "", false
+	}
+
+	return fmt.Sprintf("RSA key is %d bits, below the %d-bit minimum", info.KeySize, MinRSAKeyBits), true
+}
+
+// checkWeakSignature flags MD2, MD5 and SHA-1 based signatures. Self-signed CAs
+// are skipped, as a trust anchor's own signature is never relied upon.
+func checkWeakSignature(info *CertificateInfo) (string, bool) {
+	if info.IsCA && info.IssuerDN == info.Subject {
+		return "", false
+	}
+
+	for _, weak := range weakSignatureAlgorithms {
+		if strings.Contains(info.SignatureAlgorithm, weak) {
+			return fmt.Sprintf("certificate is signed with %s", info.SignatureAlgorithm), true
+		}
+	}
+
+	return "", false
+}
+
+// checkDeprecatedCurve flags elliptic curve keys outside approvedCurves
+func checkDeprecatedCurve(info *CertificateInfo) (string, bool) {
+	if info.KeyCurve == "" || slices.Contains(approvedCurves, info.KeyCurve) {
+		return "", false
+	}
+
+	return fmt.Sprintf("key uses deprecated curve %s", info.KeyCurve), true
+}
+
+// checkLongValidity flags leaf certificates valid for more than MaxLeafValidityDays
+func checkLongValidity(info *CertificateInfo) (string, bool) {
+	if info.IsCA || info.NotBefore.IsZero() || info.NotAfter.IsZero() {
+		return "", false
+	}
+
+	validity := info.NotAfter.Sub(info.NotBefore)
+	if validity <= MaxLeafValidityDays*24*time.Hour {
+		return "", false
+	}
+
+	return fmt.Sprintf("validity period is %d days, above the %d-day maximum", int(validity.Hours()/24), MaxLeafValidityDays), true
+}
+
+// checkMissingServerAuth flags leaf certificates whose extended key usage does not allow server
+// authentication. Without the extension any usage is allowed.
+func checkMissingServerAuth(info *CertificateInfo) (string, bool) {
+	if info.IsCA || len(info.ExtKeyUsage) == 0 {
+		return "", false
+	}
+
+	if slices.Contains(info.ExtKeyUsage, "ServerAuth") || slices.Contains(info.ExtKeyUsage, "Any") {
+		return "", false
+	}
+
+	return fmt.Sprintf("extended key usage %s does not include serverAuth", strings.Join(info.ExtKeyUsage, ", ")), true
+}
+
+// checkInsufficientSCTs flags leaf certificates that fail the browser CT policy, when it was evaluated
+func checkInsufficientSCTs(info *CertificateInfo) (string, bool) {
+	if info.CT == nil || info.CT.Compliant {
+		return "", false
+	}
+
+	return fmt.Sprintf("%d qualifying SCTs from distinct logs, browser policy requires %d", info.CT.Qualified, info.CT.Required), true
+}
@@ -0,0 +1,258 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+)
+
+func TestApplyRules_DefaultRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	healthy := CertificateInfo{
+		PublicKeyAlgorithm: "ECDSA",
+		KeySize:            256,
+		KeyCurve:           "P-256",
+		SignatureAlgorithm: "ECDSA-SHA256",
+		NotBefore:          now,
+		NotAfter:           now.AddDate(0, 0, 90),
+		ExtKeyUsage:        []string{"ServerAuth"},
+		Status:             StatusOK,
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(info *CertificateInfo)
+		wantIDs    []string
+		wantStatus Status
+	}{
+		{
+			name:       "healthy",
+			modify:     func(info *CertificateInfo) {},
+			wantStatus: StatusOK,
+		},
+		{
+			name: "weak RSA key",
+			modify: func(info *CertificateInfo) {
+				info.PublicKeyAlgorithm, info.KeySize, info.KeyCurve = "RSA", 1024, ""
+			},
+			wantIDs:    []string{RuleWeakRSAKey},
+			wantStatus: StatusCritical,
+		},
+		{
+			name: "RSA 2048 is accepted",
+			modify: func(info *CertificateInfo) {
+				info.PublicKeyAlgorithm, info.KeySize, info.KeyCurve = "RSA", 2048, ""
+			},
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "SHA-1 signature",
+			modify:     func(info *CertificateInfo) { info.SignatureAlgorithm = "SHA1-RSA" },
+			wantIDs:    []string{RuleWeakSignature},
+			wantStatus: StatusCritical,
+		},
+		{
+			name: "SHA-1 signed root is accepted",
+			modify: func(info *CertificateInfo) {
+				info.SignatureAlgorithm, info.IsCA, info.NotAfter = "SHA1-RSA", true, now.AddDate(10, 0, 0)
+				info.Subject, info.IssuerDN = "CN=Legacy Root", "CN=Legacy Root"
+			},
+			wantStatus: StatusOK,
+		},
+		{
+			name: "SHA-1 signed intermediate",
+			modify: func(info *CertificateInfo) {
+				info.SignatureAlgorithm, info.IsCA, info.NotAfter = "SHA1-RSA", true, now.AddDate(10, 0, 0)
+				info.Subject, info.IssuerDN = "CN=Legacy Intermediate", "CN=Legacy Root"
+			},
+			wantIDs:    []string{RuleWeakSignature},
+			wantStatus: StatusCritical,
+		},
+		{
+			name:       "deprecated curve",
+			modify:     func(info *CertificateInfo) { info.KeySize, info.KeyCurve = 224, "P-224" },
+			wantIDs:    []string{RuleDeprecatedCurve},
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "long validity",
+			modify:     func(info *CertificateInfo) { info.NotAfter = now.AddDate(0, 0, 399) },
+			wantIDs:    []string{RuleLongValidity},
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "398 days is accepted",
+			modify:     func(info *CertificateInfo) { info.NotAfter = now.AddDate(0, 0, 398) },
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "long-lived CA is accepted",
+			modify:     func(info *CertificateInfo) { info.IsCA, info.NotAfter = true, now.AddDate(10, 0, 0) },
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "missing serverAuth",
+			modify:     func(info *CertificateInfo) { info.ExtKeyUsage = []string{"ClientAuth"} },
+			wantIDs:    []string{RuleMissingServerAuth},
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "no extended key usage is accepted",
+			modify:     func(info *CertificateInfo) { info.ExtKeyUsage = nil },
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "insufficient SCTs",
+			modify:     func(info *CertificateInfo) { info.CT = &CTPolicy{Required: 2, Qualified: 1} },
+			wantIDs:    []string{RuleInsufficientSCTs},
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "compliant SCTs",
+			modify:     func(info *CertificateInfo) { info.CT = &CTPolicy{Required: 2, Qualified: 2, Compliant: true} },
+			wantStatus: StatusOK,
+		},
+		{
+			name: "several findings keep the worst status",
+			modify: func(info *CertificateInfo) {
+				info.SignatureAlgorithm = "ECDSA-SHA1"
+				info.NotAfter = now.AddDate(2, 0, 0)
+			},
+			wantIDs:    []string{RuleWeakSignature, RuleLongValidity},
+			wantStatus: StatusCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := healthy
+			tt.modify(&info)
+			ApplyRules(&info, DefaultRules())
+
+			if len(info.Findings) != len(tt.wantIDs) {
+				t.Fatalf("ApplyRules() findings = %+v, want IDs %v", info.Findings, tt.wantIDs)
+			}
+			for i, finding := range info.Findings {
+				if finding.ID != tt.wantIDs[i] {
+					t.Errorf("Findings[%d].ID = %q, want %q", i, finding.ID, tt.wantIDs[i])
+				}
+				if finding.Message == "" {
+					t.Errorf("Findings[%d].Message should be set", i)
+				}
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestResult_ApplyRules(t *testing.T) {
+	rule := Rule{
+		ID:       "internal_issuer",
+		Severity: SeverityCritical,
+		Check: func(info *CertificateInfo) (string, bool) {
+			return "issuer is not the internal CA", info.Issuer != "Internal CA"
+		},
+	}
+
+	result := &Result{
+		Certificates: []CertificateInfo{
+			{Host: "a.example.com:443", Issuer: "Internal CA", Status: StatusOK},
+			{Host: "b.example.com:443", Issuer: "Public CA", Status: StatusWarning},
+		},
+	}
+	result.ApplyRules([]Rule{rule})
+
+	if len(result.Certificates[0].Findings) != 0 {
+		t.Errorf("Certificates[0].Findings = %+v, want none", result.Certificates[0].Findings)
+	}
+	if len(result.Certificates[1].Findings) != 1 || result.Certificates[1].Findings[0].ID != "internal_issuer" {
+		t.Errorf("Certificates[1].Findings = %+v, want internal_issuer", result.Certificates[1].Findings)
+	}
+	if got := result.WorstStatus(); got != StatusCritical {
+		t.Errorf("WorstStatus() = %v, want %v", got, StatusCritical)
+	}
+}
+
+func TestWithoutRules(t *testing.T) {
+	rules, err := WithoutRules(DefaultRules(), []string{RuleLongValidity, RuleMissingServerAuth})
+	if err != nil {
+		t.Fatalf("WithoutRules() unexpected error: %v", err)
+	}
+
+	if len(rules) != len(DefaultRules())-2 {
+		t.Errorf("WithoutRules() returned %d rules, want %d", len(rules), len(DefaultRules())-2)
+	}
+	for _, rule := range rules {
+		if rule.ID == RuleLongValidity || rule.ID == RuleMissingServerAuth {
+			t.Errorf("WithoutRules() kept disabled rule %q", rule.ID)
+		}
+	}
+
+	if _, err := WithoutRules(DefaultRules(), []string{"no_such_rule"}); err == nil {
+		t.Error("WithoutRules() should return error for an unknown rule")
+	}
+}
+
+func TestGetCertInfoByHost_Findings(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	leaf := issueTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client.example.com"},
+		DNSNames:    []string{"client.example.com"},
+		NotAfter:    time.Now().AddDate(2, 0, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	tests := []struct {
+		name       string
+		rules      []Rule
+		wantIDs    []string
+		wantStatus Status
+	}{
+		{
+			name:       "default rules",
+			wantIDs:    []string{RuleLongValidity, RuleMissingServerAuth},
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "no rules",
+			rules:      []Rule{},
+			wantStatus: StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true, Thresholds: DefaultThresholds(), Rules: tt.rules})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, finding := range info.Findings {
+				ids = append(ids, finding.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("Findings = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("Findings[%d] = %q, want %q", i, ids[i], tt.wantIDs[i])
+				}
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package cert
+
+// Severity ranks a finding
+type Severity string
+
+// Finding is a policy violation detected on a certificate
+type Finding struct {
+	ID       string   `json:"id" yaml:"id"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// Rule is a check run against every certificate. Check returns the finding
+// message and true when the certificate violates the rule.
+type Rule struct {
+	ID       string
+	Severity Severity
+	Check    func(info *CertificateInfo) (string, bool)
+}
@@ -0,0 +1,197 @@
+package cert
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// testCert bundles a generated certificate with its private key
+type testCert struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+var testSerial int64 = 1000
+
+// issueTestCert signs the template with the parent, or self-signs when parent is nil
+func issueTestCert(t *testing.T, tmpl *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	testSerial++
+	if tmpl.SerialNumber == nil {
+		tmpl.SerialNumber = big.NewInt(testSerial)
+	}
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = time.Now().Add(-time.Hour)
+	}
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotAfter = time.Now().AddDate(0, 0, 90)
+	}
+
+	signerCert, signerKey := tmpl, crypto.Signer(key)
+	if parent != nil {
+		signerCert, signerKey = parent.Cert, parent.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signerCert, key.Public(), signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return &testCert{Cert: cert, Key: key}
+}
+
+// newTestCA creates a CA certificate, self-signed when parent is nil
+func newTestCA(t *testing.T, commonName string, parent *testCert) *testCert {
+	t.Helper()
+
+	return issueTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, parent)
+}
+
+// newTestLeaf creates a server certificate for the given names signed by parent
+func newTestLeaf(t *testing.T, parent *testCert, dnsNames ...string) *testCert {
+	t.Helper()
+
+	commonName := ""
+	if len(dnsNames) > 0 {
+		commonName = dnsNames[0]
+	}
+
+	return issueTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, parent)
+}
+
+// tlsCertificate builds a tls.Certificate serving the leaf followed by the given chain
+func (tc *testCert) tlsCertificate(chain ...*testCert) tls.Certificate {
+	certificate := tls.Certificate{
+		Certificate: [][]byte{tc.Cert.Raw},
+		PrivateKey:  tc.Key,
+		Leaf:        tc.Cert,
+	}
+	for _, c := range chain {
+		certificate.Certificate = append(certificate.Certificate, c.Cert.Raw)
+	}
+
+	return certificate
+}
+
+// writeTestPEM writes the certificates to path as a PEM bundle
+func writeTestPEM(t *testing.T, path string, certs ...*testCert) {
+	t.Helper()
+
+	var data []byte
+	for _, c := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Cert.Raw})...)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write PEM file: %v", err)
+	}
+}
+
+// startTestTLSServer serves TLS handshakes on a loopback port until the test ends
+func startTestTLSServer(t *testing.T, config *tls.Config) (string, int) {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("Failed to start TLS listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}(conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+// newTestTarget builds a plain HTTPS target for a loopback test server
+func newTestTarget(t *testing.T, host string, port int) Target {
+	t.Helper()
+
+	target, err := parseTarget(formatAddress(host, port), "")
+	if err != nil {
+		t.Fatalf("Failed to parse target: %v", err)
+	}
+
+	return target
+}
+
+// startTestUpgradeServer runs script on each plaintext connection and then
+// upgrades it to TLS, mimicking a STARTTLS capable server
+func startTestUpgradeServer(t *testing.T, config *tls.Config, script func(conn net.Conn, r *bufio.Reader) error) (string, int) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+				r := bufio.NewReader(conn)
+				if err := script(conn, r); err != nil {
+					return
+				}
+
+				// The client may send its ClientHello right behind the upgrade request
+				_ = tls.Server(&bufferedConn{Conn: conn, r: r}, config).Handshake()
+			}(conn)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
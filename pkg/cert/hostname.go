@@ -0,0 +1,72 @@
+package cert
+
+import (
+	"crypto/x509"
+	"net"
+	"strings"
+)
+
+// checkHostnames records whether cert covers every name in names, and which ones it misses
+func checkHostnames(info *CertificateInfo, cert *x509.Certificate, names []string) {
+	info.MissingNames = nil
+	for _, name := range names {
+		if !coversName(cert, name) {
+			info.MissingNames = append(info.MissingNames, name)
+		}
+	}
+
+	info.HostnameMatch = len(info.MissingNames) == 0
+}
+
+// coversName reports whether the certificate's DNS or IP SANs cover name.
+// The subject common name is ignored, as browsers do.
+func coversName(cert *x509.Certificate, name string) bool {
+	if ip := net.ParseIP(strings.Trim(name, "[]")); ip != nil {
+		for _, san := range cert.IPAddresses {
+			if san.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	name = normalizeDNSName(name)
+	for _, san := range cert.DNSNames {
+		if matchDNSName(normalizeDNSName(san), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchDNSName matches name against a SAN pattern. A wildcard only stands for
+// the whole leftmost label, so *.example.com covers www.example.com but neither
+// example.com nor a.b.example.com. An expected wildcard name is covered by the
+// same wildcard SAN.
+func matchDNSName(pattern, name string) bool {
+	if pattern == "" || name == "" {
+		return false
+	}
+
+	if pattern == name {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(pattern, "*.")
+	if !ok || strings.Contains(suffix, "*") {
+		return false
+	}
+
+	label, rest, ok := strings.Cut(name, ".")
+	if !ok || label == "" || strings.Contains(label, "*") {
+		return false
+	}
+
+	return rest == suffix
+}
+
+// normalizeDNSName lowercases a DNS name and drops a trailing root dot
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
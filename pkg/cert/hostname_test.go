@@ -0,0 +1,142 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchDNSName(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "example.com", name: "example.com", want: true},
+		{pattern: "example.com", name: "www.example.com", want: false},
+		{pattern: "*.example.com", name: "www.example.com", want: true},
+		{pattern: "*.example.com", name: "example.com", want: false},
+		{pattern: "*.example.com", name: "a.b.example.com", want: false},
+		{pattern: "*.example.com", name: "*.example.com", want: true},
+		{pattern: "*.example.com", name: "*.www.example.com", want: false},
+		{pattern: "www.*.com", name: "www.example.com", want: false},
+		{pattern: "", name: "example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			if got := matchDNSName(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("matchDNSName(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHostnames(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, ca, "example.com", "*.Example.com")
+
+	tests := []struct {
+		name        string
+		names       []string
+		wantMatch   bool
+		wantMissing []string
+	}{
+		{name: "exact", names: []string{"example.com"}, wantMatch: true},
+		{name: "wildcard and case", names: []string{"WWW.example.com."}, wantMatch: true},
+		{name: "ip san", names: []string{"127.0.0.1"}, wantMatch: true},
+		{name: "bracketed ip san", names: []string{"[127.0.0.1]"}, wantMatch: true},
+		{name: "missing", names: []string{"example.com", "example.org", "a.b.example.com", "10.0.0.1"}, wantMissing: []string{"example.org", "a.b.example.com", "10.0.0.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &CertificateInfo{}
+			checkHostnames(info, leaf.Cert, tt.names)
+
+			if info.HostnameMatch != tt.wantMatch {
+				t.Errorf("HostnameMatch = %v, want %v", info.HostnameMatch, tt.wantMatch)
+			}
+			if !reflect.DeepEqual(info.MissingNames, tt.wantMissing) {
+				t.Errorf("MissingNames = %v, want %v", info.MissingNames, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_HostnameCoverage(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, ca, "www.example.com", "api.example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	tests := []struct {
+		name          string
+		insecure      bool
+		serverName    string
+		expectedNames []string
+		wantMatch     bool
+		wantMissing   []string
+		wantStatus    Status
+	}{
+		{
+			name:       "requested name covered",
+			insecure:   true,
+			serverName: "www.example.com",
+			wantMatch:  true,
+			wantStatus: StatusOK,
+		},
+		{
+			name:        "requested name missing in insecure mode",
+			insecure:    true,
+			serverName:  "shop.example.com",
+			wantMissing: []string{"shop.example.com"},
+			wantStatus:  StatusOK,
+		},
+		{
+			name:          "expected names missing in insecure mode",
+			insecure:      true,
+			serverName:    "www.example.com",
+			expectedNames: []string{"www.example.com", "example.com", "api.example.com"},
+			wantMissing:   []string{"example.com"},
+			wantStatus:    StatusOK,
+		},
+		{
+			name:          "expected names missing",
+			serverName:    "www.example.com",
+			expectedNames: []string{"example.com"},
+			wantMissing:   []string{"example.com"},
+			wantStatus:    StatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := x509.NewCertPool()
+			roots.AddCert(ca.Cert)
+			opts := Options{Timeout: 5 * time.Second, Insecure: tt.insecure, Thresholds: DefaultThresholds(), Roots: roots}
+			checker := NewWithOptions(opts)
+
+			target := newTestTarget(t, host, port)
+			target.ServerName = tt.serverName
+			target.ExpectedNames = tt.expectedNames
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.HostnameMatch != tt.wantMatch {
+				t.Errorf("HostnameMatch = %v, want %v", info.HostnameMatch, tt.wantMatch)
+			}
+			if !reflect.DeepEqual(info.MissingNames, tt.wantMissing) {
+				t.Errorf("MissingNames = %v, want %v", info.MissingNames, tt.wantMissing)
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// oidSignedData is the PKCS#7 content type carrying certificate bundles
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// Inspect reads the certificates in local files and directories and reports them
+// like CheckHosts, without connecting anywhere. Directories are walked recursively;
+// files in them that hold no certificate or key are skipped.
+func (c *Checker) Inspect(ctx context.Context, paths []string, opts InspectOptions) (*Result, error) {
+	result := &Result{
+		Certificates: make([]CertificateInfo, 0),
+		Errors:       make([]ErrorInfo, 0),
+	}
+
+	var explicitKeys []inspectedKey
+	for _, path := range opts.KeyFiles {
+		file, err := readInspectFile(path, opts.Password)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load key file: %w", err)
+		}
+		if len(file.keys) == 0 {
+			return nil, fmt.Errorf("no private key found in key file: %s", path)
+		}
+		for _, key := range file.keys {
+			key.unpaired = true
+			explicitKeys = append(explicitKeys, key)
+		}
+	}
+
+	var files []*inspectedFile
+	for _, path := range paths {
+		found, errs := collectInspectFiles(path, opts.Password)
+		files = append(files, found...)
+		result.Errors = append(result.Errors, errs...)
+	}
+
+	// A certificate is matched against the keys in its own file and in files
+	// sharing its base name, such as tls.crt and tls.key
+	stemKeys := make(map[string][]inspectedKey)
+	for _, file := range files {
+		stemKeys[file.stem()] = append(stemKeys[file.stem()], file.keys...)
+	}
+
+	usedKeys := make([]bool, len(explicitKeys))
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		keys := append(append([]inspectedKey(nil), stemKeys[file.stem()]...), explicitKeys...)
+		for _, entry := range file.reportedEntries() {
+			info := c.inspectCertificate(ctx, entry.alias, entry.certs, file.certs, keys, nil)
+			result.Certificates = append(result.Certificates, *info)
+
+			for i, key := range explicitKeys {
+				if !entry.certs[0].IsCA && keyMatches(entry.certs[0], key) {
+					usedKeys[i] = true
+				}
+			}
+		}
+	}
+
+	// A key given with --inspect-key belongs to none of the inspected certificates
+	reported := make(map[string]bool)
+	for i, key := range explicitKeys {
+		if usedKeys[i] || reported[key.path] {
+			continue
+		}
+		reported[key.path] = true
+		result.Errors = append(result.Errors, ErrorInfo{Host: key.path, Error: "private key matches none of the inspected certificates"})
+	}
+
+	return result, nil
+}
+
+// inspectCertificate evaluates the first certificate of chain, read from a file
+// together with intermediates, the other certificates of the same file.
+// Hostname coverage is only checked when names are given.
+func (c *Checker) inspectCertificate(ctx context.Context, label string, chain, intermediates []*x509.Certificate, keys []inspectedKey, names []string) *CertificateInfo {
+	cert, certs := chain[0], chain
+	now := time.Now()
+	info := newCertificateInfo(label, cert)
+	c.thresholds.Evaluate(info, now)
+	// Files have no hostname or intended usage to verify against
+	c.verifyCertificate(info, cert, intermediates, c.roots, "", now, x509.ExtKeyUsageAny)
+	info.HostnameMatch = true
+	if len(names) > 0 {
+		checkHostnames(info, cert, names)
+	}
+	if !cert.IsCA && len(keys) > 0 {
+		checkKeyMatch(info, cert, keys)
+	}
+
+	if !c.insecure && untrusted(info, cert, now) {
+		info.Status = info.Status.Worse(StatusError)
+	}
+	if !c.insecure && !info.HostnameMatch {
+		info.Status = info.Status.Worse(StatusError)
+	}
+	// A certificate deployed with the wrong key cannot be served at all
+	if info.KeyMatch != nil && !info.KeyMatch.Matched {
+		info.Status = info.Status.Worse(StatusError)
+	}
+
+	c.checkOCSP(ctx, info, cert, certs, nil)
+	c.checkCRL(ctx, info, cert, certs)
+	c.checkSCTs(info, cert, certs, nil)
+	ApplyRules(info, c.rules)
+
+	if c.includeChain && !cert.IsCA {
+		info.Chain = c.buildChain(certs, now)
+		info.ChainIssues = analyzeChain(certs)
+	}
+
+	return info
+}
+
+// InspectSources reports the leaf certificate of every source like Inspect does
+// for files. Sources whose data cannot be decoded are recorded as errors.
+func (c *Checker) InspectSources(ctx context.Context, sources []CertificateSource) (*Result, error) {
+	result := &Result{
+		Certificates: make([]CertificateInfo, 0),
+		Errors:       make([]ErrorInfo, 0),
+	}
+
+	for _, source := range sources {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		info, err := c.inspectSource(ctx, source)
+		if err != nil {
+			result.Errors = append(result.Errors, ErrorInfo{Host: source.Label, Error: err.Error()})
+			continue
+		}
+		result.Certificates = append(result.Certificates, *info)
+	}
+
+	return result, nil
+}
+
+// inspectSource decodes a source and evaluates its leaf certificate, the first
+// non-CA certificate as for live hosts
+func (c *Checker) inspectSource(ctx context.Context, source CertificateSource) (*CertificateInfo, error) {
+	file := &inspectedFile{path: source.Label}
+	if err := file.addData(source.Certificates, ""); err != nil {
+		return nil, err
+	}
+	if len(file.certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	if len(source.Key) > 0 {
+		keyFile := &inspectedFile{path: source.Label}
+		if err := keyFile.addData(source.Key, ""); err != nil {
+			return nil, fmt.Errorf("cannot read private key: %w", err)
+		}
+		file.keys = keyFile.keys
+	}
+
+	leaf := file.certs[0]
+	for _, cert := range file.certs {
+		if !cert.IsCA {
+			leaf = cert
+			break
+		}
+	}
+	chain := []*x509.Certificate{leaf}
+	for _, cert := range file.certs {
+		if cert != leaf {
+			chain = append(chain, cert)
+		}
+	}
+
+	return c.inspectCertificate(ctx, source.Label, chain, file.certs, file.keys, source.ExpectedNames), nil
+}
+
+// checkKeyMatch records whether one of keys is the private key of cert. A mismatch
+// is only recorded when a key was found with the certificate, as unpaired keys
+// are meant for some of the inspected certificates only.
+func checkKeyMatch(info *CertificateInfo, cert *x509.Certificate, keys []inspectedKey) {
+	paired := false
+	for _, key := range keys {
+		if keyMatches(cert, key) {
+			info.KeyMatch = &KeyMatchInfo{Matched: true, KeyFile: key.path}
+			return
+		}
+		paired = paired || !key.unpaired
+	}
+
+	if paired {
+		info.KeyMatch = &KeyMatchInfo{}
+	}
+}
+
+// keyMatches reports whether key is the private key of cert
+func keyMatches(cert *x509.Certificate, key inspectedKey) bool {
+	publicKey, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(key.signer.Public())
+}
+
+// collectInspectFiles reads path, or every file below it when it is a directory.
+// Hidden directories such as .git are not descended into.
+func collectInspectFiles(root, password string) ([]*inspectedFile, []ErrorInfo) {
+	stat, err := os.Stat(root)
+	if err != nil {
+		return nil, []ErrorInfo{{Host: root, Error: fmt.Sprintf("cannot access path: %v", err)}}
+	}
+
+	if !stat.IsDir() {
+		file, err := readInspectFile(root, password)
+		if err == nil && file.empty() {
+			err = fmt.Errorf("no certificates or private keys found")
+		}
+		if err != nil {
+			return nil, []ErrorInfo{{Host: root, Error: err.Error()}}
+		}
+		return []*inspectedFile{file}, nil
+	}
+
+	var files []*inspectedFile
+	var errs []ErrorInfo
+	walkErr := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, ErrorInfo{Host: path, Error: err.Error()})
+			return nil
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinked files are followed, as in mounted Kubernetes secrets
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if stat, err := os.Stat(path); err != nil || !stat.Mode().IsRegular() {
+				return nil
+			}
+		} else if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		file, err := readInspectFile(path, password)
+		if err != nil {
+			errs = append(errs, ErrorInfo{Host: path, Error: err.Error()})
+			return nil
+		}
+		if !file.empty() {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		errs = append(errs, ErrorInfo{Host: root, Error: walkErr.Error()})
+	}
+
+	return files, errs
+}
+
+// readInspectFile reads the certificates and private keys of a PEM, DER, PKCS#7,
+// PKCS#12 or Java keystore file. A file in none of these formats is returned empty.
+func readInspectFile(path, password string) (*inspectedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	file := &inspectedFile{path: path}
+	return file, file.addData(data, password)
+}
+
+// addData adds the contents of a PEM or binary file
+func (f *inspectedFile) addData(data []byte, password string) error {
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		return f.addPEM(data)
+	}
+
+	return f.addDER(data, password)
+}
+
+// addPEM adds every certificate, PKCS#7 bundle and private key block in data
+func (f *inspectedFile) addPEM(data []byte) error {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("cannot parse certificate: %w", err)
+			}
+			f.certs = append(f.certs, cert)
+		case "PKCS7", "CMS":
+			certs, err := parsePKCS7(block.Bytes)
+			if err != nil {
+				return err
+			}
+			f.certs = append(f.certs, certs...)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			signer, err := parsePrivateKey(block.Bytes)
+			if err != nil {
+				return err
+			}
+			f.keys = append(f.keys, inspectedKey{path: f.path, signer: signer})
+		}
+	}
+}
+
+// addDER adds the contents of a binary file, trying each supported format in turn
+func (f *inspectedFile) addDER(data []byte, password string) error {
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		f.certs = certs
+		return nil
+	}
+
+	if certs, err := parsePKCS7(data); err == nil {
+		f.certs = certs
+		return nil
+	}
+
+	if signer, err := parsePrivateKey(data); err == nil {
+		f.keys = []inspectedKey{{path: f.path, signer: signer}}
+		return nil
+	}
+
+	if isJKS(data) {
+		entries, err := parseJKS(data, password)
+		if err != nil {
+			return err
+		}
+		f.entries = entries
+		for _, entry := range entries {
+			f.certs = append(f.certs, entry.certs...)
+		}
+		return nil
+	}
+
+	// Anything else that is not a PKCS#12 bundle is not a certificate file
+	if !isPKCS12(data) {
+		return nil
+	}
+
+	return f.addPKCS12(data, password)
+}
+
+// reportedEntries returns the certificates to report, each first in its chain.
+// Keystore entries are labeled path#alias; other certificates are labeled with
+// the path, numbered when the file holds several, and chained to the rest of the file.
+func (f *inspectedFile) reportedEntries() []keystoreEntry {
+	if f.entries != nil {
+		entries := make([]keystoreEntry, len(f.entries))
+		for i, entry := range f.entries {
+			entries[i] = keystoreEntry{alias: f.path + "#" + entry.alias, certs: entry.certs}
+		}
+		return entries
+	}
+
+	entries := make([]keystoreEntry, len(f.certs))
+	for i, cert := range f.certs {
+		label := f.path
+		if len(f.certs) > 1 {
+			label = fmt.Sprintf("%s#%d", f.path, i+1)
+		}
+		chain := []*x509.Certificate{cert}
+		chain = append(chain, f.certs[:i]...)
+		chain = append(chain, f.certs[i+1:]...)
+		entries[i] = keystoreEntry{alias: label, certs: chain}
+	}
+
+	return entries
+}
+
+// empty reports whether no certificate or key was found in the file
+func (f *inspectedFile) empty() bool {
+	return len(f.certs) == 0 && len(f.keys) == 0
+}
+
+// stem returns the file's path without its extension
+func (f *inspectedFile) stem() string {
+	return strings.TrimSuffix(f.path, filepath.Ext(f.path))
+}
+
+// parsePrivateKey parses a PKCS#8, PKCS#1 or SEC 1 private key
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("cannot parse private key")
+}
+
+// parsePKCS7 returns the certificates of a PKCS#7 SignedData bundle, as
+// written by "openssl crl2pkcs7" and served for .p7b/.p7c files
+func parsePKCS7(der []byte) ([]*x509.Certificate, error) {
+	input := cryptobyte.String(der)
+	var contentInfo, content, signedData cryptobyte.String
+	var contentType asn1.ObjectIdentifier
+	if !input.ReadASN1(&contentInfo, cbasn1.SEQUENCE) ||
+		!contentInfo.ReadASN1ObjectIdentifier(&contentType) {
+		return nil, fmt.Errorf("malformed PKCS#7 content info")
+	}
+	if !contentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unsupported PKCS#7 content type %s", contentType)
+	}
+
+	// SignedData: version, digestAlgorithms, encapContentInfo, [0] certificates ...
+	var certificates cryptobyte.String
+	var present bool
+	if !contentInfo.ReadASN1(&content, cbasn1.Tag(0).Constructed().ContextSpecific()) ||
+		!content.ReadASN1(&signedData, cbasn1.SEQUENCE) ||
+		!signedData.SkipASN1(cbasn1.INTEGER) ||
+		!signedData.SkipASN1(cbasn1.SET) ||
+		!signedData.SkipASN1(cbasn1.SEQUENCE) ||
+		!signedData.ReadOptionalASN1(&certificates, &present, cbasn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, fmt.Errorf("malformed PKCS#7 signed data")
+	}
+
+	var certs []*x509.Certificate
+	for !certificates.Empty() {
+		var raw cryptobyte.String
+		if !certificates.ReadASN1Element(&raw, cbasn1.SEQUENCE) {
+			return nil, fmt.Errorf("malformed PKCS#7 certificate set")
+		}
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse PKCS#7 certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in PKCS#7 bundle")
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,297 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// encodeTestPKCS7 wraps the certificates in a degenerate PKCS#7 SignedData bundle
+func encodeTestPKCS7(t *testing.T, certs ...*testCert) []byte {
+	t.Helper()
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oidSignedData)
+		b.AddASN1(cbasn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1Int64(1)
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {})
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					b.AddASN1ObjectIdentifier([]int{1, 2, 840, 113549, 1, 7, 1})
+				})
+				b.AddASN1(cbasn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+					for _, c := range certs {
+						b.AddBytes(c.Cert.Raw)
+					}
+				})
+				b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {})
+			})
+		})
+	})
+
+	der, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to encode PKCS#7: %v", err)
+	}
+
+	return der
+}
+
+// writeTestKey writes the private key of tc to path in PKCS#8 PEM form
+func writeTestKey(t *testing.T, path string, tc *testCert) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(tc.Key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+func TestParsePKCS7(t *testing.T) {
+	ca := newTestCA(t, "PKCS7 Root", nil)
+	leaf := newTestLeaf(t, ca, "p7.example.com")
+
+	certs, err := parsePKCS7(encodeTestPKCS7(t, leaf, ca))
+	if err != nil {
+		t.Fatalf("parsePKCS7() unexpected error: %v", err)
+	}
+	if len(certs) != 2 || !certs[0].Equal(leaf.Cert) || !certs[1].Equal(ca.Cert) {
+		t.Errorf("parsePKCS7() returned %d certificates, want leaf and CA", len(certs))
+	}
+
+	if _, err := parsePKCS7(encodeTestPKCS7(t)); err == nil {
+		t.Error("parsePKCS7() expected error for a bundle without certificates")
+	}
+	if _, err := parsePKCS7(leaf.Cert.Raw); err == nil {
+		t.Error("parsePKCS7() expected error for a bare certificate")
+	}
+}
+
+func TestChecker_Inspect(t *testing.T) {
+	ca := newTestCA(t, "Inspect Root", nil)
+	leaf := newTestLeaf(t, ca, "inspect.example.com")
+	other := newTestLeaf(t, ca, "other.example.com")
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	writeTestPEM(t, filepath.Join(dir, "bundle.pem"), leaf, ca)
+	writeTestPEM(t, filepath.Join(dir, "tls.crt"), leaf)
+	writeTestKey(t, filepath.Join(dir, "tls.key"), leaf)
+	writeTestPEM(t, filepath.Join(dir, "wrong.crt"), other)
+	writeTestKey(t, filepath.Join(dir, "wrong.key"), leaf)
+	write("leaf.der", leaf.Cert.Raw)
+	write("bundle.p7b", encodeTestPKCS7(t, leaf, ca))
+	writeTestPKCS12(t, filepath.Join(dir, "store.p12"), "", leaf, ca)
+	writeTestPKCS12(t, filepath.Join(dir, "locked.p12"), "secret", leaf, ca)
+	write("notes.txt", []byte("not a certificate"))
+	write("nested/deep.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Cert.Raw}))
+	write(".git/objects/cert.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Cert.Raw}))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	checker := NewWithOptions(Options{Roots: roots, Thresholds: DefaultThresholds()})
+
+	result, err := checker.Inspect(context.Background(), []string{dir, filepath.Join(dir, "missing.pem")}, InspectOptions{})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+
+	got := make(map[string]CertificateInfo)
+	for _, info := range result.Certificates {
+		got[strings.TrimPrefix(info.Host, dir+string(filepath.Separator))] = info
+	}
+
+	wantLabels := []string{
+		"bundle.pem#1", "bundle.pem#2", "tls.crt", "wrong.crt", "leaf.der",
+		"bundle.p7b#1", "bundle.p7b#2", "store.p12#1", "store.p12#2", filepath.Join("nested", "deep.pem"),
+	}
+	if len(got) != len(wantLabels) {
+		t.Errorf("Inspect() returned %d certificates, want %d: %v", len(got), len(wantLabels), got)
+	}
+	for _, label := range wantLabels {
+		info, ok := got[label]
+		if !ok {
+			t.Errorf("Inspect() missing certificate %s", label)
+			continue
+		}
+		if !info.Verified || !info.HostnameMatch {
+			t.Errorf("%s: Verified = %v, HostnameMatch = %v, want both true", label, info.Verified, info.HostnameMatch)
+		}
+	}
+
+	if match := got["tls.crt"].KeyMatch; match == nil || !match.Matched || match.KeyFile != filepath.Join(dir, "tls.key") {
+		t.Errorf("tls.crt KeyMatch = %+v, want matched by tls.key", match)
+	}
+	if match := got["store.p12#1"].KeyMatch; match == nil || !match.Matched {
+		t.Errorf("store.p12 KeyMatch = %+v, want matched by the bundled key", match)
+	}
+	if info := got["wrong.crt"]; info.KeyMatch == nil || info.KeyMatch.Matched || info.Status != StatusError {
+		t.Errorf("wrong.crt KeyMatch = %+v, Status = %s, want mismatch and ERROR", info.KeyMatch, info.Status)
+	}
+	if info := got["bundle.pem#2"]; info.KeyMatch != nil || info.Status != StatusOK {
+		t.Errorf("CA certificate KeyMatch = %+v, Status = %s, want no key check and OK", info.KeyMatch, info.Status)
+	}
+	if got["leaf.der"].KeyMatch != nil {
+		t.Errorf("leaf.der KeyMatch = %+v, want nil without a key", got["leaf.der"].KeyMatch)
+	}
+
+	wantErrors := map[string]string{
+		"locked.p12":  "password protected",
+		"missing.pem": "cannot access path",
+	}
+	if len(result.Errors) != len(wantErrors) {
+		t.Errorf("Inspect() returned errors %v, want %d", result.Errors, len(wantErrors))
+	}
+	for _, e := range result.Errors {
+		want, ok := wantErrors[filepath.Base(e.Host)]
+		if !ok || !strings.Contains(e.Error, want) {
+			t.Errorf("Inspect() unexpected error for %s: %s", e.Host, e.Error)
+		}
+	}
+}
+
+func TestChecker_Inspect_KeyFiles(t *testing.T) {
+	ca := newTestCA(t, "Key Root", nil)
+	leaf := newTestLeaf(t, ca, "key.example.com")
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.pem")
+	keyFile := filepath.Join(dir, "private", "server-key.pem")
+	writeTestPEM(t, certFile, leaf)
+	if err := os.Mkdir(filepath.Dir(keyFile), 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTestKey(t, keyFile, leaf)
+
+	checker := NewWithOptions(Options{Insecure: true, Thresholds: DefaultThresholds()})
+
+	result, err := checker.Inspect(context.Background(), []string{certFile}, InspectOptions{KeyFiles: []string{keyFile}})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Certificates) != 1 {
+		t.Fatalf("Inspect() returned %d certificates, want 1", len(result.Certificates))
+	}
+	if match := result.Certificates[0].KeyMatch; match == nil || !match.Matched || match.KeyFile != keyFile {
+		t.Errorf("KeyMatch = %+v, want matched by %s", match, keyFile)
+	}
+
+	// The key belongs to one of two certificates; the other one is not a mismatch
+	otherFile := filepath.Join(dir, "other.pem")
+	writeTestPEM(t, otherFile, newTestLeaf(t, ca, "other.example.com"))
+	result, err = checker.Inspect(context.Background(), []string{certFile, otherFile}, InspectOptions{KeyFiles: []string{keyFile}})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Certificates) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("Inspect() = %+v, want two certificates and no errors", result)
+	}
+	if info := result.Certificates[1]; info.KeyMatch != nil || info.Status != StatusOK {
+		t.Errorf("other.pem KeyMatch = %+v, Status = %s, want no key check and OK", info.KeyMatch, info.Status)
+	}
+
+	// A key matching no inspected certificate is an error
+	result, err = checker.Inspect(context.Background(), []string{otherFile}, InspectOptions{KeyFiles: []string{keyFile}})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Host != keyFile {
+		t.Errorf("Inspect() errors = %+v, want the unmatched key file reported", result.Errors)
+	}
+
+	if _, err := checker.Inspect(context.Background(), []string{certFile}, InspectOptions{KeyFiles: []string{certFile}}); err == nil {
+		t.Error("Inspect() expected error for a key file without a private key")
+	}
+
+	emptyFile := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(emptyFile, []byte("nothing here"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	result, err = checker.Inspect(context.Background(), []string{emptyFile}, InspectOptions{})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error, "no certificates") {
+		t.Errorf("Inspect() errors = %v, want no certificates error", result.Errors)
+	}
+}
+
+func TestChecker_InspectSources(t *testing.T) {
+	ca := newTestCA(t, "Source Root", nil)
+	leaf := newTestLeaf(t, ca, "source.example.com")
+	other := newTestLeaf(t, ca, "other.example.com")
+
+	encode := func(certs ...*testCert) []byte {
+		var data []byte
+		for _, c := range certs {
+			data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Cert.Raw})...)
+		}
+		return data
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(leaf.Key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	checker := NewWithOptions(Options{Roots: roots, Thresholds: DefaultThresholds()})
+
+	sources := []CertificateSource{
+		// CA first, as some issuers write the chain in reverse order
+		{Label: "web/site-tls", Certificates: encode(ca, leaf), Key: key, ExpectedNames: []string{"source.example.com"}},
+		{Label: "web/stale-tls", Certificates: encode(other, ca), ExpectedNames: []string{"source.example.com"}},
+		{Label: "web/wrong-key", Certificates: encode(other), Key: key},
+		{Label: "web/garbage", Certificates: []byte("not a certificate")},
+	}
+
+	result, err := checker.InspectSources(context.Background(), sources)
+	if err != nil {
+		t.Fatalf("InspectSources() unexpected error: %v", err)
+	}
+
+	got := make(map[string]CertificateInfo)
+	for _, info := range result.Certificates {
+		got[info.Host] = info
+	}
+	if len(got) != 3 {
+		t.Fatalf("InspectSources() returned %d certificates, want 3", len(got))
+	}
+
+	if info := got["web/site-tls"]; info.CommonName != "source.example.com" || !info.Verified || info.Status != StatusOK ||
+		info.KeyMatch == nil || !info.KeyMatch.Matched {
+		t.Errorf("web/site-tls = %+v, want the verified leaf with its key", info)
+	}
+	if info := got["web/stale-tls"]; info.HostnameMatch || info.Status != StatusError {
+		t.Errorf("web/stale-tls HostnameMatch = %v, Status = %s, want mismatch and ERROR", info.HostnameMatch, info.Status)
+	}
+	if info := got["web/wrong-key"]; info.KeyMatch == nil || info.KeyMatch.Matched || info.Status != StatusError {
+		t.Errorf("web/wrong-key KeyMatch = %+v, Status = %s, want mismatch and ERROR", info.KeyMatch, info.Status)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Host != "web/garbage" {
+		t.Errorf("InspectSources() errors = %v, want one for web/garbage", result.Errors)
+	}
+}
@@ -0,0 +1,52 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+)
+
+// InspectOptions configures Checker.Inspect
+type InspectOptions struct {
+	// KeyFiles are private keys matched against every inspected leaf certificate,
+	// in addition to the keys found next to it. A leaf they do not match is not
+	// reported as a mismatch; a key matching no leaf is reported as an error.
+	KeyFiles []string
+	// Password opens PKCS#12 bundles and checks the integrity of Java keystores
+	Password string
+}
+
+// CertificateSource is certificate data read from somewhere other than a plain
+// file, such as a Kubernetes Secret, and reported under Label
+type CertificateSource struct {
+	Label string
+	// Certificates is PEM or DER data holding the certificate and its chain
+	Certificates []byte
+	// Key is the certificate's private key in PEM or DER form, when available
+	Key []byte
+	// ExpectedNames must all be covered by the certificate's SANs; empty skips the check
+	ExpectedNames []string
+}
+
+// KeyMatchInfo reports whether a private key found for a certificate belongs to it
+type KeyMatchInfo struct {
+	Matched bool   `json:"matched" yaml:"matched"`
+	KeyFile string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+}
+
+// inspectedFile holds the certificates and private keys read from one file
+type inspectedFile struct {
+	path string
+	// certs is every certificate in the file, in file order
+	certs []*x509.Certificate
+	// entries are the keystore aliases; nil reports every certificate instead
+	entries []keystoreEntry
+	keys    []inspectedKey
+}
+
+// inspectedKey is a private key and the file it was read from
+type inspectedKey struct {
+	path   string
+	signer crypto.Signer
+	// unpaired keys were given separately rather than found with a certificate
+	unpaired bool
+}
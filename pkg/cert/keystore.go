@@ -0,0 +1,271 @@
+package cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// oidData is the PKCS#7 content type of a PKCS#12 authenticated safe
+var oidData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+
+const (
+	jksMagic   = 0xFEEDFEED
+	jceksMagic = 0xCECECECE
+
+	jksTagPrivateKey  = 1
+	jksTagTrustedCert = 2
+	jksTagSecretKey   = 3
+
+	// jksDigestSalt is mixed into the keystore integrity digest by the JDK
+	jksDigestSalt = "Mighty Aphrodite"
+)
+
+// isJKS reports whether data starts with the JKS or JCEKS magic number
+func isJKS(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	magic := binary.BigEndian.Uint32(data)
+	return magic == jksMagic || magic == jceksMagic
+}
+
+// isPKCS12 reports whether data is a DER PFX structure: a sequence
+// starting with version 3 and a data content info
+func isPKCS12(data []byte) bool {
+	input := cryptobyte.String(data)
+	var pfx, authSafe cryptobyte.String
+	var version int
+	var contentType asn1.ObjectIdentifier
+	return input.ReadASN1(&pfx, cbasn1.SEQUENCE) &&
+		pfx.ReadASN1Integer(&version) && version == 3 &&
+		pfx.ReadASN1(&authSafe, cbasn1.SEQUENCE) &&
+		authSafe.ReadASN1ObjectIdentifier(&contentType) &&
+		contentType.Equal(oidData)
+}
+
+// parseJKS returns the entries of a JKS or JCEKS keystore. Certificates are
+// stored unencrypted, so the password is only needed to check the integrity
+// digest, which is skipped when password is empty.
+func parseJKS(data []byte, password string) ([]keystoreEntry, error) {
+	if len(data) < sha1.Size {
+		return nil, fmt.Errorf("truncated Java keystore")
+	}
+	body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if password != "" && !bytes.Equal(jksDigest(body, password), digest) {
+		return nil, fmt.Errorf("incorrect keystore password or corrupted Java keystore")
+	}
+
+	input := cryptobyte.String(body)
+	var magic, version, count uint32
+	if !input.ReadUint32(&magic) || !input.ReadUint32(&version) || !input.ReadUint32(&count) {
+		return nil, fmt.Errorf("malformed Java keystore header")
+	}
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported Java keystore version %d", version)
+	}
+
+	// count comes from the file and is not trusted to size anything; a count larger
+	// than the data fails on the first missing entry. Non-nil even when empty.
+	entries := []keystoreEntry{}
+	for i := uint32(0); i < count; i++ {
+		var tag uint32
+		var alias cryptobyte.String
+		var timestamp uint64
+		if !input.ReadUint32(&tag) || !input.ReadUint16LengthPrefixed(&alias) || !input.ReadUint64(&timestamp) {
+			return nil, fmt.Errorf("malformed Java keystore entry")
+		}
+
+		entry := keystoreEntry{alias: string(alias)}
+		switch tag {
+		case jksTagPrivateKey:
+			var key cryptobyte.String
+			var chainLength uint32
+			if !readUint32Prefixed(&input, &key) || !input.ReadUint32(&chainLength) {
+				return nil, fmt.Errorf("malformed private key entry %q", entry.alias)
+			}
+			for j := uint32(0); j < chainLength; j++ {
+				cert, err := readJKSCertificate(&input, version)
+				if err != nil {
+					return nil, fmt.Errorf("entry %q: %w", entry.alias, err)
+				}
+				entry.certs = append(entry.certs, cert)
+			}
+		case jksTagTrustedCert:
+			cert, err := readJKSCertificate(&input, version)
+			if err != nil {
+				return nil, fmt.Errorf("entry %q: %w", entry.alias, err)
+			}
+			entry.certs = []*x509.Certificate{cert}
+		case jksTagSecretKey:
+			// Secret keys are serialized Java objects that cannot be skipped without decoding them
+			return nil, fmt.Errorf("entry %q: JCEKS secret key entries are not supported", entry.alias)
+		default:
+			return nil, fmt.Errorf("entry %q: unknown Java keystore entry type %d", entry.alias, tag)
+		}
+
+		if len(entry.certs) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// readJKSCertificate reads one certificate, preceded by its type in version 2 keystores
+func readJKSCertificate(input *cryptobyte.String, version uint32) (*x509.Certificate, error) {
+	if version == 2 {
+		var certType cryptobyte.String
+		if !input.ReadUint16LengthPrefixed(&certType) {
+			return nil, fmt.Errorf("malformed certificate type")
+		}
+		if string(certType) != "X.509" {
+			return nil, fmt.Errorf("unsupported certificate type %q", string(certType))
+		}
+	}
+
+	var der cryptobyte.String
+	if !readUint32Prefixed(input, &der) {
+		return nil, fmt.Errorf("malformed certificate")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+// readUint32Prefixed reads a value preceded by its 32-bit length
+func readUint32Prefixed(input *cryptobyte.String, out *cryptobyte.String) bool {
+	var length uint32
+	return input.ReadUint32(&length) && input.ReadBytes((*[]byte)(out), int(length))
+}
+
+// jksDigest computes the keystore integrity digest over the password as
+// UTF-16BE, the JDK's fixed salt and the keystore body
+func jksDigest(body []byte, password string) []byte {
+	h := sha1.New()
+	for _, unit := range utf16.Encode([]rune(password)) {
+		h.Write([]byte{byte(unit >> 8), byte(unit)})
+	}
+	h.Write([]byte(jksDigestSalt))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
+
+// addPKCS12 adds the certificates and keys of a PKCS#12 bundle, with one
+// entry per friendly name. Bundles without friendly names, or in a layout the
+// alias-aware decoder rejects, are added without entries.
+func (f *inspectedFile) addPKCS12(data []byte, password string) error {
+	if err := f.addPKCS12Entries(data, password); err == nil {
+		return nil
+	} else if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return pkcs12PasswordError(password)
+	}
+
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err == nil {
+		f.certs = append([]*x509.Certificate{leaf}, caCerts...)
+		if signer, ok := key.(crypto.Signer); ok {
+			f.keys = []inspectedKey{{path: f.path, signer: signer}}
+		}
+		return nil
+	}
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return pkcs12PasswordError(password)
+	}
+
+	// Java trust stores hold certificates only, each marked as a trust anchor
+	certs, err := pkcs12.DecodeTrustStore(data, password)
+	if err != nil {
+		return fmt.Errorf("cannot decode PKCS#12 file: %w", err)
+	}
+	f.certs = certs
+
+	return nil
+}
+
+// addPKCS12Entries decodes every bag of a PKCS#12 bundle, grouping certificates
+// by friendly name or by the local key ID shared with a named key. Unnamed
+// certificates are taken as the chain of every entry.
+func (f *inspectedFile) addPKCS12Entries(data []byte, password string) error {
+	// ToPEM is the only decoder exposing the bag attributes that carry aliases
+	blocks, err := pkcs12.ToPEM(data, password) //nolint:staticcheck
+	if err != nil {
+		return err
+	}
+
+	var keys []inspectedKey
+	keyAliases := make(map[string]string)
+	for _, block := range blocks {
+		if block.Type != "PRIVATE KEY" {
+			continue
+		}
+		signer, err := parsePrivateKey(block.Bytes)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, inspectedKey{path: f.path, signer: signer})
+		if id := block.Headers["localKeyId"]; id != "" {
+			keyAliases[id] = block.Headers["friendlyName"]
+		}
+	}
+
+	var certs, unnamed []*x509.Certificate
+	var entries []keystoreEntry
+	byAlias := make(map[string]int)
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("cannot parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+
+		alias := block.Headers["friendlyName"]
+		if alias == "" {
+			alias = keyAliases[block.Headers["localKeyId"]]
+		}
+		if alias == "" {
+			unnamed = append(unnamed, cert)
+			continue
+		}
+		if i, ok := byAlias[alias]; ok {
+			entries[i].certs = append(entries[i].certs, cert)
+			continue
+		}
+		byAlias[alias] = len(entries)
+		entries = append(entries, keystoreEntry{alias: alias, certs: []*x509.Certificate{cert}})
+	}
+
+	for i := range entries {
+		entries[i].certs = append(entries[i].certs, unnamed...)
+	}
+
+	f.certs, f.entries, f.keys = certs, entries, keys
+
+	return nil
+}
+
+// pkcs12PasswordError explains a PKCS#12 MAC failure
+func pkcs12PasswordError(password string) error {
+	if password == "" {
+		return fmt.Errorf("PKCS#12 file is password protected")
+	}
+	return fmt.Errorf("incorrect PKCS#12 password")
+}
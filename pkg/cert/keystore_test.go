@@ -0,0 +1,280 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// testJKSEntry is a keystore entry written by encodeTestJKS
+type testJKSEntry struct {
+	tag   uint32
+	alias string
+	certs []*testCert
+}
+
+// encodeTestJKS writes a version 2 Java keystore, sealed with password
+func encodeTestJKS(t *testing.T, password string, entries ...testJKSEntry) []byte {
+	t.Helper()
+
+	var b cryptobyte.Builder
+	b.AddUint32(jksMagic)
+	b.AddUint32(2)
+	b.AddUint32(uint32(len(entries)))
+	addCert := func(b *cryptobyte.Builder, c *testCert) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte("X.509")) })
+		b.AddUint32(uint32(len(c.Cert.Raw)))
+		b.AddBytes(c.Cert.Raw)
+	}
+	for _, entry := range entries {
+		b.AddUint32(entry.tag)
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte(entry.alias)) })
+		b.AddUint64(1700000000000)
+		switch entry.tag {
+		case jksTagPrivateKey:
+			// The protected key is opaque to the reader
+			key := []byte("protected key")
+			b.AddUint32(uint32(len(key)))
+			b.AddBytes(key)
+			b.AddUint32(uint32(len(entry.certs)))
+			for _, c := range entry.certs {
+				addCert(&b, c)
+			}
+		case jksTagTrustedCert:
+			addCert(&b, entry.certs[0])
+		}
+	}
+
+	body, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to encode keystore: %v", err)
+	}
+
+	return append(body, jksDigest(body, password)...)
+}
+
+// encodeTestPKCS12Bags writes an unencrypted, MAC-less PKCS#12 bundle whose
+// certificate bags carry the given friendly names, empty meaning none
+func encodeTestPKCS12Bags(t *testing.T, certs []*testCert, names []string) []byte {
+	t.Helper()
+
+	oidCertBag := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	explicit := cbasn1.Tag(0).Constructed().ContextSpecific()
+
+	dataContent := func(b *cryptobyte.Builder, content func(b *cryptobyte.Builder)) {
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(oidData)
+			b.AddASN1(explicit, func(b *cryptobyte.Builder) {
+				b.AddASN1(cbasn1.OCTET_STRING, content)
+			})
+		})
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(3)
+		dataContent(b, func(b *cryptobyte.Builder) {
+			b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				dataContent(b, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+						for i, c := range certs {
+							b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+								b.AddASN1ObjectIdentifier(oidCertBag)
+								b.AddASN1(explicit, func(b *cryptobyte.Builder) {
+									b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+										b.AddASN1ObjectIdentifier(oidX509Certificate)
+										b.AddASN1(explicit, func(b *cryptobyte.Builder) {
+											b.AddASN1OctetString(c.Cert.Raw)
+										})
+									})
+								})
+								if names[i] == "" {
+									return
+								}
+								b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+									b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+										b.AddASN1ObjectIdentifier(oidFriendlyName)
+										b.AddASN1(cbasn1.SET, func(b *cryptobyte.Builder) {
+											b.AddASN1(cbasn1.Tag(30), func(b *cryptobyte.Builder) {
+												for _, unit := range utf16.Encode([]rune(names[i])) {
+													b.AddUint16(unit)
+												}
+											})
+										})
+									})
+								})
+							})
+						}
+					})
+				})
+				// ToPEM expects a second safe, as written for the private key
+				dataContent(b, func(b *cryptobyte.Builder) {
+					b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {})
+				})
+			})
+		})
+	})
+
+	der, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to encode PKCS#12: %v", err)
+	}
+
+	return der
+}
+
+func TestParseJKS(t *testing.T) {
+	ca := newTestCA(t, "JKS Root", nil)
+	leaf := newTestLeaf(t, ca, "jks.example.com")
+
+	keystore := encodeTestJKS(t, "changeit",
+		testJKSEntry{tag: jksTagPrivateKey, alias: "server", certs: []*testCert{leaf, ca}},
+		testJKSEntry{tag: jksTagTrustedCert, alias: "root", certs: []*testCert{ca}},
+	)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		password string
+		wantErr  string
+	}{
+		{name: "correct password", data: keystore, password: "changeit"},
+		{name: "no password skips the digest", data: keystore},
+		{name: "wrong password", data: keystore, password: "wrong", wantErr: "incorrect keystore password"},
+		{name: "truncated", data: keystore[:40], wantErr: "malformed"},
+		{
+			// A header claiming billions of entries, followed only by the digest
+			name:    "huge entry count",
+			data:    append([]byte{0xfe, 0xed, 0xfe, 0xed, 0, 0, 0, 2, 0xff, 0xff, 0xff, 0xf0}, make([]byte, 20)...),
+			wantErr: "malformed Java keystore entry",
+		},
+		{
+			name:    "secret key entry",
+			data:    encodeTestJKS(t, "", testJKSEntry{tag: jksTagSecretKey, alias: "aes"}),
+			wantErr: "secret key entries are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseJKS(tt.data, tt.password)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseJKS() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJKS() unexpected error: %v", err)
+			}
+
+			if len(entries) != 2 {
+				t.Fatalf("parseJKS() returned %d entries, want 2", len(entries))
+			}
+			if entries[0].alias != "server" || len(entries[0].certs) != 2 || !entries[0].certs[0].Equal(leaf.Cert) {
+				t.Errorf("parseJKS() entry 0 = %s with %d certificates, want server with leaf and CA", entries[0].alias, len(entries[0].certs))
+			}
+			if entries[1].alias != "root" || len(entries[1].certs) != 1 || !entries[1].certs[0].Equal(ca.Cert) {
+				t.Errorf("parseJKS() entry 1 = %s with %d certificates, want root with the CA", entries[1].alias, len(entries[1].certs))
+			}
+		})
+	}
+}
+
+func TestIsKeystore(t *testing.T) {
+	ca := newTestCA(t, "Magic Root", nil)
+
+	jks := encodeTestJKS(t, "", testJKSEntry{tag: jksTagTrustedCert, alias: "root", certs: []*testCert{ca}})
+	jceks := append([]byte(nil), jks...)
+	binary.BigEndian.PutUint32(jceks, jceksMagic)
+	p12 := encodeTestPKCS12Bags(t, []*testCert{ca}, []string{"root"})
+
+	if !isJKS(jks) || !isJKS(jceks) || isJKS(p12) || isJKS(ca.Cert.Raw) {
+		t.Error("isJKS() did not tell keystores from other files")
+	}
+	if !isPKCS12(p12) || isPKCS12(jks) || isPKCS12(ca.Cert.Raw) {
+		t.Error("isPKCS12() did not tell PKCS#12 bundles from other files")
+	}
+}
+
+func TestChecker_Inspect_Keystores(t *testing.T) {
+	ca := newTestCA(t, "Keystore Root", nil)
+	leaf := newTestLeaf(t, ca, "keystore.example.com")
+	other := newTestLeaf(t, ca, "other.example.com")
+
+	dir := t.TempDir()
+	jksFile := filepath.Join(dir, "app.jks")
+	keystore := encodeTestJKS(t, "changeit",
+		testJKSEntry{tag: jksTagPrivateKey, alias: "tomcat", certs: []*testCert{leaf, ca}},
+		testJKSEntry{tag: jksTagTrustedCert, alias: "internal-root", certs: []*testCert{ca}},
+	)
+	if err := os.WriteFile(jksFile, keystore, 0o600); err != nil {
+		t.Fatalf("Failed to write keystore: %v", err)
+	}
+	writeTestPKCS12(t, filepath.Join(dir, "locked.p12"), "changeit", leaf, ca)
+
+	// Bundles without a MAC only open without a password
+	namedFile := filepath.Join(t.TempDir(), "named.p12")
+	if err := os.WriteFile(namedFile, encodeTestPKCS12Bags(t, []*testCert{other, ca}, []string{"api", ""}), 0o600); err != nil {
+		t.Fatalf("Failed to write PKCS#12: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	checker := NewWithOptions(Options{Roots: roots, Thresholds: DefaultThresholds()})
+
+	result, err := checker.Inspect(context.Background(), []string{dir}, InspectOptions{Password: "changeit"})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Inspect() unexpected errors: %v", result.Errors)
+	}
+
+	want := map[string]string{
+		jksFile + "#tomcat":                     "keystore.example.com",
+		jksFile + "#internal-root":              "Keystore Root",
+		filepath.Join(dir, "locked.p12") + "#1": "keystore.example.com",
+		filepath.Join(dir, "locked.p12") + "#2": "Keystore Root",
+	}
+	if len(result.Certificates) != len(want) {
+		t.Errorf("Inspect() returned %d certificates, want %d", len(result.Certificates), len(want))
+	}
+	for _, info := range result.Certificates {
+		commonName, ok := want[info.Host]
+		if !ok {
+			t.Errorf("Inspect() unexpected certificate %s", info.Host)
+			continue
+		}
+		if info.CommonName != commonName || !info.Verified {
+			t.Errorf("%s: CommonName = %s, Verified = %v, want %s verified", info.Host, info.CommonName, info.Verified, commonName)
+		}
+	}
+
+	result, err = checker.Inspect(context.Background(), []string{namedFile}, InspectOptions{})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Certificates) != 1 || result.Certificates[0].Host != namedFile+"#api" || !result.Certificates[0].Verified {
+		t.Errorf("Inspect() = %+v, want the verified api entry", result.Certificates)
+	}
+
+	result, err = checker.Inspect(context.Background(), []string{filepath.Join(dir, "locked.p12")}, InspectOptions{Password: "wrong"})
+	if err != nil {
+		t.Fatalf("Inspect() unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error, "incorrect PKCS#12 password") {
+		t.Errorf("Inspect() errors = %v, want incorrect password", result.Errors)
+	}
+}
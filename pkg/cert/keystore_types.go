@@ -0,0 +1,10 @@
+package cert
+
+import "crypto/x509"
+
+// keystoreEntry is one alias of a Java keystore or PKCS#12 bundle, with the
+// entry's certificate first and the rest of its chain after it
+type keystoreEntry struct {
+	alias string
+	certs []*x509.Certificate
+}
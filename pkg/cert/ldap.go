@@ -0,0 +1,178 @@
+package cert
+
+import (
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	// ldapStartTLSOID identifies the StartTLS extended operation
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+	berTagInteger           = 0x02
+	berTagOctetString       = 0x04
+	berTagEnumerated        = 0x0a
+	berTagSequence          = 0x30
+	ldapTagExtendedRequest  = 0x77
+	ldapTagExtendedResponse = 0x78
+	ldapTagRequestName      = 0x80
+	ldapResultSuccess       = 0
+	ldapStartTLSMessageID   = 1
+)
+
+func (ldapNegotiator) Name() string     { return "ldap" }
+func (ldapNegotiator) DefaultPort() int { return 389 }
+
+func (ldapNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	if _, err := conn.Write(ldapStartTLSRequest()); err != nil {
+		return fmt.Errorf("sending StartTLS request: %w", err)
+	}
+
+	tag, message, err := readBERElement(conn)
+	if err != nil {
+		return fmt.Errorf("reading StartTLS response: %w", err)
+	}
+	if tag != berTagSequence {
+		return fmt.Errorf("unexpected LDAP message tag: 0x%02x", tag)
+	}
+
+	resultCode, diagnostic, err := parseLDAPExtendedResponse(message)
+	if err != nil {
+		return err
+	}
+
+	if resultCode != ldapResultSuccess {
+		return fmt.Errorf("StartTLS rejected with result code %d: %s", resultCode, diagnostic)
+	}
+
+	return nil
+}
+
+// ldapStartTLSRequest encodes the StartTLS ExtendedRequest LDAPMessage
+func ldapStartTLSRequest() []byte {
+	request := berEncode(ldapTagExtendedRequest, berEncode(ldapTagRequestName, []byte(ldapStartTLSOID)))
+	messageID := berEncode(berTagInteger, []byte{ldapStartTLSMessageID})
+
+	return berEncode(berTagSequence, append(messageID, request...))
+}
+
+// parseLDAPExtendedResponse extracts the result code and diagnostic message from an LDAPMessage body
+func parseLDAPExtendedResponse(message []byte) (int, string, error) {
+	tag, _, rest, err := parseBERElement(message)
+	if err != nil || tag != berTagInteger {
+		return 0, "", fmt.Errorf("malformed LDAP message id")
+	}
+
+	tag, response, _, err := parseBERElement(rest)
+	if err != nil {
+		return 0, "", fmt.Errorf("malformed LDAP response: %w", err)
+	}
+	if tag != ldapTagExtendedResponse {
+		return 0, "", fmt.Errorf("unexpected LDAP response tag: 0x%02x", tag)
+	}
+
+	tag, code, rest, err := parseBERElement(response)
+	if err != nil || tag != berTagEnumerated || len(code) == 0 {
+		return 0, "", fmt.Errorf("malformed LDAP result code")
+	}
+
+	resultCode := 0
+	for _, b := range code {
+		resultCode = resultCode<<8 | int(b)
+	}
+
+	// matchedDN followed by diagnosticMessage
+	diagnostic := ""
+	if _, _, rest, err = parseBERElement(rest); err == nil {
+		if tag, value, _, err := parseBERElement(rest); err == nil && tag == berTagOctetString {
+			diagnostic = string(value)
+		}
+	}
+
+	return resultCode, diagnostic, nil
+}
+
+// berEncode encodes a single BER element with a definite length
+func berEncode(tag byte, content []byte) []byte {
+	length := len(content)
+
+	var header []byte
+	switch {
+	case length < 0x80:
+		header = []byte{tag, byte(length)}
+	case length <= 0xff:
+		header = []byte{tag, 0x81, byte(length)}
+	default:
+		header = []byte{tag, 0x82, byte(length >> 8), byte(length)}
+	}
+
+	return append(header, content...)
+}
+
+// readBERElement reads one complete BER element from r
+func readBERElement(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return 0, nil, fmt.Errorf("unsupported BER length encoding")
+		}
+
+		lengthBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, lengthBytes); err != nil {
+			return 0, nil, err
+		}
+
+		length = 0
+		for _, b := range lengthBytes {
+			length = length<<8 | int(b)
+		}
+	}
+
+	if length > maxNegotiationBytes {
+		return 0, nil, fmt.Errorf("BER element too large: %d bytes", length)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], content, nil
+}
+
+// parseBERElement splits the first BER element off data
+func parseBERElement(data []byte) (tag byte, content, rest []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, fmt.Errorf("truncated BER element")
+	}
+
+	tag = data[0]
+	length := int(data[1])
+	pos := 2
+
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 || len(data) < pos+n {
+			return 0, nil, nil, fmt.Errorf("invalid BER length")
+		}
+
+		length = 0
+		for _, b := range data[pos : pos+n] {
+			length = length<<8 | int(b)
+		}
+		pos += n
+	}
+
+	if len(data) < pos+length {
+		return 0, nil, nil, fmt.Errorf("truncated BER element")
+	}
+
+	return tag, data[pos : pos+length], data[pos+length:], nil
+}
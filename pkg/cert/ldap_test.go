@@ -0,0 +1,118 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLDAPServer answers a StartTLS extended request with the given result code
+func fakeLDAPServer(resultCode byte, diagnostic string) func(conn net.Conn, r *bufio.Reader) error {
+	return func(conn net.Conn, r *bufio.Reader) error {
+		tag, message, err := readBERElement(r)
+		if err != nil {
+			return err
+		}
+		if tag != berTagSequence || !bytes.Contains(message, []byte(ldapStartTLSOID)) {
+			return fmt.Errorf("unexpected LDAP request")
+		}
+
+		response := berEncode(berTagEnumerated, []byte{resultCode})
+		response = append(response, berEncode(berTagOctetString, nil)...)
+		response = append(response, berEncode(berTagOctetString, []byte(diagnostic))...)
+		body := append(berEncode(berTagInteger, []byte{ldapStartTLSMessageID}), berEncode(ldapTagExtendedResponse, response)...)
+
+		conn.Write(berEncode(berTagSequence, body))
+		if resultCode != ldapResultSuccess {
+			return fmt.Errorf("starttls refused")
+		}
+		return nil
+	}
+}
+
+func TestLDAPNegotiator(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "ldap.example.com")
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}}
+
+	tests := []struct {
+		name    string
+		server  func(conn net.Conn, r *bufio.Reader) error
+		wantErr string
+	}{
+		{
+			name:   "StartTLS accepted",
+			server: fakeLDAPServer(ldapResultSuccess, ""),
+		},
+		{
+			name:    "StartTLS rejected",
+			server:  fakeLDAPServer(2, "StartTLS not configured"),
+			wantErr: "result code 2: StartTLS not configured",
+		},
+	}
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := startTestUpgradeServer(t, tlsConfig, tt.server)
+
+			target, err := parseTarget(fmt.Sprintf("ldap://%s:%d", host, port), "")
+			if err != nil {
+				t.Fatalf("parseTarget() unexpected error: %v", err)
+			}
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("getCertInfoByHost() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.CommonName != "ldap.example.com" {
+				t.Errorf("getCertInfoByHost() CommonName = %q, want ldap.example.com", info.CommonName)
+			}
+		})
+	}
+}
+
+func TestLDAPS_ImplicitTLS(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "ldap.example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	target, err := parseTarget(fmt.Sprintf("ldaps://%s:%d", host, port), "")
+	if err != nil {
+		t.Fatalf("parseTarget() unexpected error: %v", err)
+	}
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+	info, err := checker.getCertInfoByHost(context.Background(), target)
+	if err != nil {
+		t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+	}
+
+	if info.Host != fmt.Sprintf("ldaps://%s:%d", host, port) {
+		t.Errorf("getCertInfoByHost() Host = %q, want ldaps label", info.Host)
+	}
+}
+
+func TestLDAPStartTLSRequest_Encoding(t *testing.T) {
+	want := append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, []byte(ldapStartTLSOID)...)
+
+	if got := ldapStartTLSRequest(); !bytes.Equal(got, want) {
+		t.Errorf("ldapStartTLSRequest() = % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,4 @@
+package cert
+
+// ldapNegotiator upgrades LDAP sessions with the StartTLS extended operation (RFC 4511)
+type ldapNegotiator struct{}
@@ -0,0 +1,155 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	OCSPStatusGood    OCSPStatus = "good"
+	OCSPStatusRevoked OCSPStatus = "revoked"
+	OCSPStatusUnknown OCSPStatus = "unknown"
+
+	OCSPSourceStaple    = "staple"
+	OCSPSourceResponder = "responder"
+
+	// maxOCSPResponseBytes bounds the size of a responder reply
+	maxOCSPResponseBytes = 1024 * 1024
+)
+
+// checkOCSP records the OCSP status of the leaf from the stapled response and,
+// when enabled, from the responder listed in the certificate
+func (c *Checker) checkOCSP(ctx context.Context, info *CertificateInfo, leaf *x509.Certificate, certs []*x509.Certificate, staple []byte) {
+	if len(staple) == 0 && !c.ocsp {
+		return
+	}
+
+	result := &OCSPInfo{Stapled: len(staple) > 0}
+	info.OCSP = result
+
+	issuer := findIssuer(leaf, certs)
+	if issuer == nil {
+		result.Error = "issuer certificate was not presented"
+		return
+	}
+
+	if len(staple) > 0 {
+		resp, err := ocsp.ParseResponseForCert(staple, leaf, issuer)
+		if err != nil {
+			result.Error = fmt.Sprintf("invalid stapled response: %v", err)
+		} else {
+			applyOCSPResponse(result, resp, OCSPSourceStaple)
+		}
+	}
+
+	if c.ocsp {
+		resp, responder, err := c.queryOCSP(ctx, leaf, issuer)
+		result.Responder = responder
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Error = ""
+			applyOCSPResponse(result, resp, OCSPSourceResponder)
+		}
+	}
+
+	if result.Status == OCSPStatusRevoked {
+		info.Status = info.Status.Worse(StatusRevoked)
+	}
+}
+
+// queryOCSP asks the responders listed in the leaf for its status, returning the first answer
+func (c *Checker) queryOCSP(ctx context.Context, leaf, issuer *x509.Certificate) (*ocsp.Response, string, error) {
+	if len(leaf.OCSPServer) == 0 {
+		return nil, "", fmt.Errorf("certificate does not list an OCSP responder")
+	}
+
+	request, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+
+	var lastErr error
+	for _, responder := range leaf.OCSPServer {
+		resp, err := c.postOCSP(ctx, responder, request, leaf, issuer)
+		if err == nil {
+			return resp, responder, nil
+		}
+		lastErr = fmt.Errorf("OCSP responder %s: %w", responder, err)
+	}
+
+	return nil, leaf.OCSPServer[len(leaf.OCSPServer)-1], lastErr
+}
+
+// postOCSP sends a single OCSP request and parses the reply
+func (c *Checker) postOCSP(ctx context.Context, responder string, request []byte, leaf, issuer *x509.Certificate) (*ocsp.Response, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctxWithTimeout, http.MethodPost, responder, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+	httpReq.Header.Set("Accept", "application/ocsp-response")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", httpResp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxOCSPResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return ocsp.ParseResponseForCert(body, leaf, issuer)
+}
+
+// applyOCSPResponse copies a parsed OCSP response into result, replacing the
+// details of any response applied before
+func applyOCSPResponse(result *OCSPInfo, resp *ocsp.Response, source string) {
+	result.Source = source
+	result.ThisUpdate = resp.ThisUpdate
+	result.NextUpdate = resp.NextUpdate
+	result.RevokedAt = time.Time{}
+	result.RevocationReason = 0
+
+	switch resp.Status {
+	case ocsp.Good:
+		result.Status = OCSPStatusGood
+	case ocsp.Revoked:
+		result.Status = OCSPStatusRevoked
+		result.RevokedAt = resp.RevokedAt
+		result.RevocationReason = resp.RevocationReason
+	default:
+		result.Status = OCSPStatusUnknown
+	}
+}
+
+// findIssuer returns the presented certificate that signed cert, if any
+func findIssuer(cert *x509.Certificate, certs []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range certs {
+		if candidate == nil || candidate == cert {
+			continue
+		}
+
+		if bytes.Equal(cert.RawIssuer, candidate.RawSubject) && cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+
+	return nil
+}
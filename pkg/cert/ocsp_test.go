@@ -0,0 +1,210 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// newTestOCSPResponse signs an OCSP response for serial with the issuer's key
+func newTestOCSPResponse(t *testing.T, issuer *testCert, serial *big.Int, status int) []byte {
+	t.Helper()
+
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: serial,
+		ThisUpdate:   time.Now().Add(-time.Hour),
+		NextUpdate:   time.Now().Add(24 * time.Hour),
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+		template.RevocationReason = ocsp.KeyCompromise
+	}
+
+	resp, err := ocsp.CreateResponse(issuer.Cert, issuer.Cert, template, issuer.Key)
+	if err != nil {
+		t.Fatalf("Failed to create OCSP response: %v", err)
+	}
+
+	return resp
+}
+
+// startTestOCSPResponder answers every OCSP request with the given status
+func startTestOCSPResponder(t *testing.T, issuer *testCert, status int) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(newTestOCSPResponse(t, issuer, req.SerialNumber, status))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestCheckOCSP(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+
+	tests := []struct {
+		name        string
+		staple      int
+		responder   int
+		queryOCSP   bool
+		wantStapled bool
+		wantStatus  OCSPStatus
+		wantSource  string
+		wantCert    Status
+	}{
+		{
+			name:        "good staple",
+			staple:      ocsp.Good,
+			wantStapled: true,
+			wantStatus:  OCSPStatusGood,
+			wantSource:  OCSPSourceStaple,
+			wantCert:    StatusOK,
+		},
+		{
+			name:        "revoked staple",
+			staple:      ocsp.Revoked,
+			wantStapled: true,
+			wantStatus:  OCSPStatusRevoked,
+			wantSource:  OCSPSourceStaple,
+			wantCert:    StatusRevoked,
+		},
+		{
+			name:       "responder reports revoked",
+			staple:     -1,
+			responder:  ocsp.Revoked,
+			queryOCSP:  true,
+			wantStatus: OCSPStatusRevoked,
+			wantSource: OCSPSourceResponder,
+			wantCert:   StatusRevoked,
+		},
+		{
+			name:        "responder overrides staple",
+			staple:      ocsp.Good,
+			responder:   ocsp.Unknown,
+			queryOCSP:   true,
+			wantStapled: true,
+			wantStatus:  OCSPStatusUnknown,
+			wantSource:  OCSPSourceResponder,
+			wantCert:    StatusOK,
+		},
+		{
+			name:        "responder clears revoked staple",
+			staple:      ocsp.Revoked,
+			responder:   ocsp.Good,
+			queryOCSP:   true,
+			wantStapled: true,
+			wantStatus:  OCSPStatusGood,
+			wantSource:  OCSPSourceResponder,
+			wantCert:    StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responderURL := startTestOCSPResponder(t, intermediate, tt.responder)
+			leaf := issueTestCert(t, &x509.Certificate{
+				Subject:     pkix.Name{CommonName: "example.com"},
+				DNSNames:    []string{"example.com"},
+				OCSPServer:  []string{responderURL},
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}, intermediate)
+
+			certificate := leaf.tlsCertificate(intermediate)
+			if tt.staple >= 0 {
+				certificate.OCSPStaple = newTestOCSPResponse(t, intermediate, leaf.Cert.SerialNumber, tt.staple)
+			}
+
+			host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{certificate}})
+
+			checker := NewWithOptions(Options{
+				Timeout:    5 * time.Second,
+				Insecure:   true,
+				Thresholds: DefaultThresholds(),
+				OCSP:       tt.queryOCSP,
+			})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.OCSP == nil {
+				t.Fatal("getCertInfoByHost() OCSP is nil")
+			}
+
+			if info.OCSP.Stapled != tt.wantStapled {
+				t.Errorf("OCSP.Stapled = %v, want %v", info.OCSP.Stapled, tt.wantStapled)
+			}
+
+			if info.OCSP.Status != tt.wantStatus {
+				t.Errorf("OCSP.Status = %q, want %q (error: %s)", info.OCSP.Status, tt.wantStatus, info.OCSP.Error)
+			}
+
+			if info.OCSP.Source != tt.wantSource {
+				t.Errorf("OCSP.Source = %q, want %q", info.OCSP.Source, tt.wantSource)
+			}
+
+			if tt.wantStatus == OCSPStatusRevoked && info.OCSP.RevokedAt.IsZero() {
+				t.Error("OCSP.RevokedAt should be set for revoked certificates")
+			}
+
+			if tt.wantStatus != OCSPStatusRevoked && (!info.OCSP.RevokedAt.IsZero() || info.OCSP.RevocationReason != 0) {
+				t.Errorf("OCSP revocation details = %v, %d, want none for status %q", info.OCSP.RevokedAt, info.OCSP.RevocationReason, info.OCSP.Status)
+			}
+
+			if info.Status != tt.wantCert {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantCert)
+			}
+		})
+	}
+}
+
+func TestCheckOCSP_NoStapleNoQuery(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+	info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+	if err != nil {
+		t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+	}
+
+	if info.OCSP != nil {
+		t.Errorf("getCertInfoByHost() OCSP = %+v, want nil without staple or --ocsp", info.OCSP)
+	}
+}
+
+func TestCheckOCSP_MissingIssuer(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	leaf := newTestLeaf(t, intermediate, "example.com")
+
+	checker := NewWithOptions(Options{Timeout: time.Second, OCSP: true})
+	info := &CertificateInfo{Status: StatusOK}
+	checker.checkOCSP(context.Background(), info, leaf.Cert, []*x509.Certificate{leaf.Cert}, nil)
+
+	if info.OCSP == nil || info.OCSP.Error == "" {
+		t.Errorf("checkOCSP() should report an error when the issuer is missing, got %+v", info.OCSP)
+	}
+}
@@ -0,0 +1,23 @@
+package cert
+
+import (
+	"time"
+)
+
+// OCSPStatus is the revocation status reported by an OCSP response
+type OCSPStatus string
+
+// OCSPInfo describes the OCSP revocation status of a leaf certificate
+type OCSPInfo struct {
+	// Stapled reports whether the server stapled an OCSP response to the handshake
+	Stapled bool       `json:"stapled" yaml:"stapled"`
+	Status  OCSPStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	// Source is "staple" or "responder", depending on where Status came from
+	Source           string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Responder        string    `json:"responder,omitempty" yaml:"responder,omitempty"`
+	ThisUpdate       time.Time `json:"this_update,omitzero" yaml:"this_update,omitempty"`
+	NextUpdate       time.Time `json:"next_update,omitzero" yaml:"next_update,omitempty"`
+	RevokedAt        time.Time `json:"revoked_at,omitzero" yaml:"revoked_at,omitempty"`
+	RevocationReason int       `json:"revocation_reason,omitempty" yaml:"revocation_reason,omitempty"`
+	Error            string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
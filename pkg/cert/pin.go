@@ -0,0 +1,87 @@
+package cert
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ValidatePin checks that s is a certificate fingerprint or SPKI pin
+func ValidatePin(s string) error {
+	_, err := parsePin(s)
+	return err
+}
+
+// parsePin accepts a certificate SHA-256 fingerprint in hex, with or without
+// colons (as in sha256_fingerprint), or a base64 SPKI SHA-256, optionally
+// prefixed with "sha256/" (as in spki_sha256 and HPKP)
+func parsePin(s string) (pin, error) {
+	s = strings.TrimSpace(s)
+
+	if encoded, ok := strings.CutPrefix(s, "sha256/"); ok {
+		return parseSPKIPin(encoded, s)
+	}
+
+	if raw, err := hex.DecodeString(strings.ReplaceAll(s, ":", "")); err == nil && len(raw) == sha256.Size {
+		var p pin
+		copy(p.digest[:], raw)
+		return p, nil
+	}
+
+	return parseSPKIPin(s, s)
+}
+
+// parseSPKIPin decodes a base64 SPKI SHA-256; original is used in errors
+func parseSPKIPin(encoded, original string) (pin, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != sha256.Size {
+		return pin{}, fmt.Errorf("invalid pin %q: expected a hex SHA-256 certificate fingerprint or a base64 SHA-256 SPKI hash", original)
+	}
+
+	p := pin{spki: true}
+	copy(p.digest[:], raw)
+	return p, nil
+}
+
+// parsePins parses every pin of a target
+func parsePins(values []string) ([]pin, error) {
+	pins := make([]pin, 0, len(values))
+	for _, value := range values {
+		p, err := parsePin(value)
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, p)
+	}
+
+	return pins, nil
+}
+
+// matches reports whether the pin is the hash of cert or of its public key
+func (p pin) matches(cert *x509.Certificate) bool {
+	if p.spki {
+		return sha256.Sum256(cert.RawSubjectPublicKeyInfo) == p.digest
+	}
+
+	return sha256.Sum256(cert.Raw) == p.digest
+}
+
+// checkPins records whether any presented certificate matches one of the pins.
+// A mismatch overrides the verification outcome with VerifyFailurePinMismatch.
+func checkPins(info *CertificateInfo, certs []*x509.Certificate, pins []pin) {
+	for _, p := range pins {
+		for _, cert := range certs {
+			if cert != nil && p.matches(cert) {
+				info.PinMatched = true
+				return
+			}
+		}
+	}
+
+	info.Verified = false
+	info.VerificationFailure = VerifyFailurePinMismatch
+	info.VerificationError = fmt.Sprintf("none of the %d presented certificates matches the %d configured pins", len(certs), len(pins))
+}
@@ -0,0 +1,140 @@
+package cert
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePin(t *testing.T) {
+	digest := sha256.Sum256([]byte("pin"))
+	encoded := base64.StdEncoding.EncodeToString(digest[:])
+
+	tests := []struct {
+		name     string
+		input    string
+		wantSPKI bool
+		wantErr  bool
+	}{
+		{name: "colon hex fingerprint", input: colonHex(digest[:]), wantSPKI: false},
+		{name: "plain hex fingerprint", input: strings.ToLower(strings.ReplaceAll(colonHex(digest[:]), ":", "")), wantSPKI: false},
+		{name: "base64 SPKI", input: encoded, wantSPKI: true},
+		{name: "prefixed SPKI", input: " sha256/" + encoded + " ", wantSPKI: true},
+		{name: "short hex", input: "AB:CD", wantErr: true},
+		{name: "SHA-1 sized base64", input: base64.StdEncoding.EncodeToString(digest[:20]), wantErr: true},
+		{name: "prefixed hex", input: "sha256/" + colonHex(digest[:]), wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePin(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got.spki != tt.wantSPKI {
+				t.Errorf("parsePin() spki = %v, want %v", got.spki, tt.wantSPKI)
+			}
+			if got.digest != digest {
+				t.Errorf("parsePin() digest = %x, want %x", got.digest, digest)
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_Pins(t *testing.T) {
+	ca := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, ca, "www.example.com")
+	other := newTestLeaf(t, ca, "www.example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate(ca)}})
+
+	leafFingerprint := sha256.Sum256(leaf.Cert.Raw)
+	caSPKI := sha256.Sum256(ca.Cert.RawSubjectPublicKeyInfo)
+	otherFingerprint := sha256.Sum256(other.Cert.Raw)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	tests := []struct {
+		name        string
+		insecure    bool
+		pins        []string
+		wantMatched bool
+		wantFailure VerifyFailure
+		wantStatus  Status
+		wantErr     bool
+	}{
+		{
+			name:        "leaf fingerprint",
+			pins:        []string{colonHex(otherFingerprint[:]), colonHex(leafFingerprint[:])},
+			wantMatched: true,
+			wantStatus:  StatusOK,
+		},
+		{
+			name:        "issuer SPKI",
+			pins:        []string{"sha256/" + base64.StdEncoding.EncodeToString(caSPKI[:])},
+			wantMatched: true,
+			wantStatus:  StatusOK,
+		},
+		{
+			name:        "mismatch",
+			pins:        []string{colonHex(otherFingerprint[:])},
+			wantFailure: VerifyFailurePinMismatch,
+			wantStatus:  StatusError,
+		},
+		{
+			name:     "invalid pin",
+			insecure: true,
+			pins:     []string{other.Cert.Subject.CommonName + "-not-a-pin"},
+			wantErr:  true,
+		},
+		{
+			name:        "mismatch still fails in insecure mode",
+			insecure:    true,
+			pins:        []string{colonHex(otherFingerprint[:])},
+			wantFailure: VerifyFailurePinMismatch,
+			wantStatus:  StatusError,
+		},
+		{
+			name:       "no pins",
+			wantStatus: StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: tt.insecure, Thresholds: DefaultThresholds(), Roots: roots})
+
+			target := newTestTarget(t, host, port)
+			target.ServerName = "www.example.com"
+			target.Pins = tt.pins
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getCertInfoByHost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if info.PinMatched != tt.wantMatched {
+				t.Errorf("PinMatched = %v, want %v", info.PinMatched, tt.wantMatched)
+			}
+			if info.VerificationFailure != tt.wantFailure {
+				t.Errorf("VerificationFailure = %q, want %q", info.VerificationFailure, tt.wantFailure)
+			}
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
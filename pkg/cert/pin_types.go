@@ -0,0 +1,9 @@
+package cert
+
+import "crypto/sha256"
+
+// pin is a parsed SHA-256 pin of a certificate or of its SubjectPublicKeyInfo
+type pin struct {
+	spki   bool
+	digest [sha256.Size]byte
+}
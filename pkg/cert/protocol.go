@@ -0,0 +1,56 @@
+package cert
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+const DefaultScheme = "https"
+
+// negotiators holds every supported scheme, keyed by the negotiator name
+var negotiators = map[string]Negotiator{
+	"https":    implicitTLS{name: "https", port: DefaultPort},
+	"smtps":    implicitTLS{name: "smtps", port: 465},
+	"imaps":    implicitTLS{name: "imaps", port: 993},
+	"pop3s":    implicitTLS{name: "pop3s", port: 995},
+	"ftps":     implicitTLS{name: "ftps", port: 990},
+	"ldaps":    implicitTLS{name: "ldaps", port: 636},
+	"smtp":     smtpNegotiator{},
+	"imap":     imapNegotiator{},
+	"pop3":     pop3Negotiator{},
+	"ftp":      ftpNegotiator{},
+	"xmpp":     xmppNegotiator{},
+	"postgres": postgresNegotiator{},
+	"mysql":    mysqlNegotiator{},
+	"ldap":     ldapNegotiator{},
+}
+
+// LookupNegotiator returns the negotiator of the scheme
+func LookupNegotiator(scheme string) (Negotiator, bool) {
+	n, ok := negotiators[strings.ToLower(scheme)]
+	return n, ok
+}
+
+// SupportedSchemes returns the supported scheme names in sorted order
+func SupportedSchemes() []string {
+	schemes := make([]string, 0, len(negotiators))
+	for name := range negotiators {
+		schemes = append(schemes, name)
+	}
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+func (p implicitTLS) Name() string {
+	return p.name
+}
+
+func (p implicitTLS) DefaultPort() int {
+	return p.port
+}
+
+func (p implicitTLS) Negotiate(conn net.Conn, hostname string) error {
+	return nil
+}
@@ -0,0 +1,20 @@
+package cert
+
+import (
+	"testing"
+)
+
+func TestNegotiators(t *testing.T) {
+	for scheme, negotiator := range negotiators {
+		if negotiator.Name() != scheme {
+			t.Errorf("negotiators[%q].Name() = %q, want the scheme it is listed under", scheme, negotiator.Name())
+		}
+	}
+
+	if n, ok := LookupNegotiator("SMTP"); !ok || n.Name() != "smtp" {
+		t.Errorf("LookupNegotiator(SMTP) = %v, %v, want the smtp negotiator", n, ok)
+	}
+	if _, ok := LookupNegotiator("gopher"); ok {
+		t.Error("LookupNegotiator(gopher) should not find a negotiator")
+	}
+}
@@ -0,0 +1,21 @@
+package cert
+
+import (
+	"net"
+)
+
+// Negotiator performs the plaintext exchange a protocol requires before the TLS handshake
+type Negotiator interface {
+	// Name returns the URL scheme selecting this negotiator
+	Name() string
+	// DefaultPort returns the port used when a host entry does not specify one
+	DefaultPort() int
+	// Negotiate runs on the freshly dialed connection and returns once the server is ready for TLS
+	Negotiate(conn net.Conn, hostname string) error
+}
+
+// implicitTLS is a Negotiator for protocols that start TLS immediately
+type implicitTLS struct {
+	name string
+	port int
+}
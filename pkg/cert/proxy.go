@@ -0,0 +1,447 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	socks5Version     = 0x05
+	socks5AuthNone    = 0x00
+	socks5AuthUser    = 0x02
+	socks5AuthNoMatch = 0xff
+	socks5CmdConnect  = 0x01
+	socks5AddrIPv4    = 0x01
+	socks5AddrDomain  = 0x03
+	socks5AddrIPv6    = 0x04
+)
+
+// ParseProxyURL parses a proxy given as http://[user:pass@]host[:port] or
+// socks5://[user:pass@]host[:port]; a bare host:port means an HTTP proxy
+func ParseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("proxy cannot be empty")
+	}
+
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s (supported: http, socks5, socks5h)", proxyURL.Scheme)
+	}
+
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("proxy host cannot be empty")
+	}
+
+	return proxyURL, nil
+}
+
+// newProxyDialer builds a dialer using the explicit proxy, or the proxy
+// configured in the environment when it is nil. resolver looks up host names
+// for socks5 proxies.
+func newProxyDialer(proxy *url.URL, forward Dialer, resolver Resolver) *proxyDialer {
+	d := &proxyDialer{
+		proxy:    proxy,
+		noProxy:  splitNoProxy(getenvAny("NO_PROXY", "no_proxy")),
+		forward:  forward,
+		resolver: resolver,
+	}
+
+	if proxy == nil {
+		for _, raw := range []string{getenvAny("HTTPS_PROXY", "https_proxy"), getenvAny("ALL_PROXY", "all_proxy")} {
+			if raw == "" {
+				continue
+			}
+			// A malformed variable is ignored, as other tools do
+			if envProxy, err := ParseProxyURL(raw); err == nil {
+				d.envProxy = envProxy
+				break
+			}
+		}
+	}
+
+	return d
+}
+
+// proxyFor returns the proxy used to reach address, or nil to connect directly
+func (d *proxyDialer) proxyFor(address string) *url.URL {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	if matchNoProxy(d.noProxy, host, port) {
+		return nil
+	}
+
+	if d.proxy != nil {
+		return d.proxy
+	}
+
+	// Like net/http, environment proxies are never used for the local machine
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	return d.envProxy
+}
+
+// DialContext connects to address, tunnelling through the selected proxy
+func (d *proxyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	proxyURL := d.proxyFor(address)
+	if proxyURL == nil {
+		return d.forward.DialContext(ctx, network, address)
+	}
+
+	conn, err := d.forward.DialContext(ctx, network, proxyAddress(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxyURL.Host, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var tunnel net.Conn
+	switch proxyURL.Scheme {
+	case "http":
+		tunnel, err = httpConnect(conn, proxyURL, address)
+	case "socks5":
+		// socks5 resolves host names locally and sends the address, socks5h leaves it to the proxy
+		var resolved string
+		resolved, err = d.resolve(ctx, address)
+		if err == nil {
+			err = socks5Connect(conn, proxyURL, resolved)
+		}
+		tunnel = conn
+	default:
+		err = socks5Connect(conn, proxyURL, address)
+		tunnel = conn
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %w", proxyURL.Host, err)
+	}
+
+	return tunnel, nil
+}
+
+// resolve replaces the host name of address with its first IP address
+func (d *proxyDialer) resolve(ctx context.Context, address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return address, nil
+	}
+
+	addrs, err := d.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("failed to resolve %s: no addresses found", host)
+	}
+
+	return net.JoinHostPort(addrs[0].IP.String(), port), nil
+}
+
+// httpProxy returns the proxy for an outgoing HTTP request, for OCSP and CRL downloads
+func (d *proxyDialer) httpProxy(req *http.Request) (*url.URL, error) {
+	address := req.URL.Host
+	if req.URL.Port() == "" {
+		address = net.JoinHostPort(req.URL.Hostname(), "80")
+		if req.URL.Scheme == "https" {
+			address = net.JoinHostPort(req.URL.Hostname(), "443")
+		}
+	}
+
+	return d.proxyFor(address), nil
+}
+
+// httpConnect opens a tunnel to address with an HTTP CONNECT request
+func httpConnect(conn net.Conn, proxyURL *url.URL, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CONNECT to %s refused: %s", address, resp.Status)
+	}
+
+	// Protocols such as SMTP greet right away, possibly in the same packet as the CONNECT reply
+	if r.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: r}, nil
+	}
+
+	return conn, nil
+}
+
+// socks5Connect asks a SOCKS5 proxy (RFC 1928) to connect to address,
+// authenticating with username and password (RFC 1929) when the URL has them
+func socks5Connect(conn net.Conn, proxyURL *url.URL, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port: %s", portStr)
+	}
+
+	methods := []byte{socks5AuthNone}
+	if proxyURL.User != nil {
+		methods = append(methods, socks5AuthUser)
+	}
+	greeting := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 greeting: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 greeting reply: %w", err)
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("unexpected SOCKS version: %d", reply[0])
+	}
+
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthUser:
+		if err := socks5Authenticate(conn, proxyURL.User); err != nil {
+			return err
+		}
+	case socks5AuthNoMatch:
+		return fmt.Errorf("no acceptable SOCKS5 authentication method")
+	default:
+		return fmt.Errorf("unsupported SOCKS5 authentication method: %d", reply[1])
+	}
+
+	request := []byte{socks5Version, socks5CmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			request = append(append(request, socks5AddrIPv4), ip4...)
+		} else {
+			request = append(append(request, socks5AddrIPv6), ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("hostname too long for SOCKS5: %s", host)
+		}
+		request = append(append(request, socks5AddrDomain, byte(len(host))), host...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 connect request: %w", err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 connect reply: %w", err)
+	}
+	if header[1] != 0x00 {
+		return fmt.Errorf("SOCKS5 connect to %s failed: %s", address, socks5ReplyText(header[1]))
+	}
+
+	// Skip the bound address, which is of no interest here
+	var skip int
+	switch header[3] {
+	case socks5AddrIPv4:
+		skip = net.IPv4len
+	case socks5AddrIPv6:
+		skip = net.IPv6len
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("failed to read SOCKS5 connect reply: %w", err)
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("unexpected SOCKS5 address type: %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 connect reply: %w", err)
+	}
+
+	return nil
+}
+
+// socks5Authenticate performs the username/password sub-negotiation
+func socks5Authenticate(conn net.Conn, user *url.Userinfo) error {
+	username := user.Username()
+	password, _ := user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf("SOCKS5 credentials too long")
+	}
+
+	request := []byte{0x01, byte(len(username))}
+	request = append(request, username...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 credentials: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 authentication reply: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("SOCKS5 authentication failed")
+	}
+
+	return nil
+}
+
+// socks5ReplyText describes a SOCKS5 reply code
+func socks5ReplyText(code byte) string {
+	switch code {
+	case 0x01:
+		return "general failure"
+	case 0x02:
+		return "connection not allowed by ruleset"
+	case 0x03:
+		return "network unreachable"
+	case 0x04:
+		return "host unreachable"
+	case 0x05:
+		return "connection refused"
+	case 0x06:
+		return "TTL expired"
+	case 0x07:
+		return "command not supported"
+	case 0x08:
+		return "address type not supported"
+	default:
+		return fmt.Sprintf("unknown error %d", code)
+	}
+}
+
+// proxyAddress returns the proxy's host:port, applying the scheme's default port
+func proxyAddress(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		port = "1080"
+		if proxyURL.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// splitNoProxy splits a NO_PROXY value into its entries
+func splitNoProxy(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// matchNoProxy reports whether host:port is excluded from proxying. Entries are
+// "*", IP addresses, CIDR ranges, or domain names that also match their
+// subdomains, each optionally followed by ":port".
+func matchNoProxy(entries []string, host, port string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, entry := range entries {
+		if entry == "*" {
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost = strings.TrimPrefix(strings.TrimPrefix(entryHost, "*"), ".")
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getenvAny returns the first non-empty environment variable among keys
+func getenvAny(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,31 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/url"
+)
+
+// Dialer opens the TCP connection to a target; *net.Dialer satisfies it
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// proxyDialer connects through an HTTP CONNECT or SOCKS5 proxy unless the
+// address is excluded by NO_PROXY
+type proxyDialer struct {
+	// proxy is the explicit proxy; nil falls back to the environment
+	proxy *url.URL
+	// envProxy is HTTPS_PROXY or ALL_PROXY, used for non-loopback addresses
+	envProxy *url.URL
+	noProxy  []string
+	forward  Dialer
+	resolver Resolver
+}
+
+// bufferedConn reads through a bufio.Reader that may already hold data from the peer
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
@@ -0,0 +1,69 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// expandTarget resolves the target's hostname and returns one target per
+// distinct address, each keeping the original server name for SNI and verification
+func (c *Checker) expandTarget(ctx context.Context, target Target) ([]Target, error) {
+	if net.ParseIP(target.Hostname) != nil {
+		return []Target{target}, nil
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	addrs, err := c.resolver.LookupIPAddr(ctxWithTimeout, target.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", target.Hostname, err)
+	}
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", target.Hostname)
+	}
+
+	serverName := target.ServerName
+	if serverName == "" {
+		serverName = target.Hostname
+	}
+
+	seen := make(map[string]bool, len(addrs))
+	endpoints := make([]Target, 0, len(addrs))
+	for _, addr := range addrs {
+		ip := addr.IP.String()
+		if seen[ip] {
+			continue
+		}
+		seen[ip] = true
+
+		endpoint := target
+		endpoint.Hostname = ip
+		endpoint.ServerName = serverName
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+// markEndpointMismatches flags certificates of hosts whose addresses served different certificates
+func markEndpointMismatches(result *Result, groups map[string][]int) {
+	for _, indices := range groups {
+		identities := make(map[string]bool)
+		for _, i := range indices {
+			certInfo := result.Certificates[i]
+			identities[certInfo.Issuer+"/"+certInfo.SerialNumber] = true
+		}
+
+		if len(identities) < 2 {
+			continue
+		}
+
+		for _, i := range indices {
+			result.Certificates[i].EndpointMismatch = true
+			result.Certificates[i].Status = result.Certificates[i].Status.Worse(StatusWarning)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers lookups from a static table
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return addrs, nil
+}
+
+// startTestTLSServerAt serves TLS on a specific loopback address
+func startTestTLSServerAt(t *testing.T, address string, config *tls.Config) {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", address, config)
+	if err != nil {
+		t.Skipf("Cannot listen on %s: %v", address, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}(conn)
+		}
+	}()
+}
+
+func TestCheckHosts_AllIPs(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	current := newTestLeaf(t, root, "www.example.com")
+	stale := newTestLeaf(t, root, "www.example.com")
+
+	_, port := startTestTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{current.tlsCertificate()},
+	})
+
+	tests := []struct {
+		name         string
+		secondCert   *testCert
+		wantMismatch bool
+		wantStatus   Status
+	}{
+		{name: "consistent endpoints", secondCert: current, wantMismatch: false, wantStatus: StatusOK},
+		{name: "stale endpoint", secondCert: stale, wantMismatch: true, wantStatus: StatusWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secondIP := "127.0.0.2"
+			startTestTLSServerAt(t, fmt.Sprintf("%s:%d", secondIP, port), &tls.Config{
+				Certificates: []tls.Certificate{tt.secondCert.tlsCertificate()},
+			})
+
+			checker := NewWithOptions(Options{
+				Timeout:    5 * time.Second,
+				Insecure:   true,
+				Thresholds: DefaultThresholds(),
+				AllIPs:     true,
+				Resolver:   fakeResolver{"www.example.com": {"127.0.0.1", secondIP, "127.0.0.1"}},
+			})
+
+			result, err := checker.CheckCertificates(context.Background(), []string{fmt.Sprintf("www.example.com:%d", port)})
+			if err != nil {
+				t.Fatalf("CheckCertificates() unexpected error: %v", err)
+			}
+
+			if len(result.Certificates) != 2 {
+				t.Fatalf("CheckCertificates() certificates = %d, want 2 (errors: %v)", len(result.Certificates), result.Errors)
+			}
+
+			for _, certInfo := range result.Certificates {
+				if !strings.HasPrefix(certInfo.Host, "www.example.com@127.0.0.") {
+					t.Errorf("CheckCertificates() Host = %q, want per-IP label", certInfo.Host)
+				}
+
+				if certInfo.EndpointMismatch != tt.wantMismatch {
+					t.Errorf("%s EndpointMismatch = %v, want %v", certInfo.Host, certInfo.EndpointMismatch, tt.wantMismatch)
+				}
+
+				if certInfo.Status != tt.wantStatus {
+					t.Errorf("%s Status = %v, want %v", certInfo.Host, certInfo.Status, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckHosts_AllIPsResolveError(t *testing.T) {
+	checker := NewWithOptions(Options{
+		Timeout:  time.Second,
+		AllIPs:   true,
+		Resolver: fakeResolver{},
+	})
+
+	result, err := checker.CheckCertificates(context.Background(), []string{"missing.example.com"})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error, "failed to resolve") {
+		t.Errorf("CheckCertificates() errors = %v, want a resolution error", result.Errors)
+	}
+}
@@ -0,0 +1,11 @@
+package cert
+
+import (
+	"context"
+	"net"
+)
+
+// Resolver looks up the addresses of a hostname; *net.Resolver satisfies it
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
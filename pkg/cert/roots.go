@@ -0,0 +1,103 @@
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadCertPool builds a root pool from PEM bundles and directories of PEM files,
+// starting from the system roots when system is true. It returns nil when
+// there is nothing to add to the system roots.
+func LoadCertPool(paths []string, system bool) (*x509.CertPool, error) {
+	if len(paths) == 0 && system {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if system {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("cannot load system roots: %w", err)
+		}
+		pool = systemPool
+	}
+
+	for _, path := range paths {
+		if err := addCertsFromPath(pool, path); err != nil {
+			return nil, err
+		}
+	}
+
+	return pool, nil
+}
+
+// addCertsFromPath adds the certificates of a PEM file, or of every PEM file in a directory
+func addCertsFromPath(pool *x509.CertPool, path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot access CA path: %w", err)
+	}
+
+	if !stat.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no PEM certificates found in CA file: %s", path)
+		}
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("cannot read CA directory: %w", err)
+	}
+
+	found := false
+	for _, entry := range entries {
+		// Hashed links such as those in /etc/ssl/certs are followed by ReadFile
+		if entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if pool.AppendCertsFromPEM(data) {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no PEM certificates found in CA directory: %s", path)
+	}
+
+	return nil
+}
+
+// rootsFor returns the root pool used to verify target, loading and caching
+// its per-host CA the first time it is needed
+func (c *Checker) rootsFor(target Target) (*x509.CertPool, error) {
+	if target.CA == "" {
+		return c.roots, nil
+	}
+
+	c.loadMutex.Lock()
+	defer c.loadMutex.Unlock()
+
+	if pool, ok := c.hostRoots[target.CA]; ok {
+		return pool, nil
+	}
+
+	pool, err := LoadCertPool([]string{target.CA}, false)
+	if err != nil {
+		return nil, err
+	}
+	c.hostRoots[target.CA] = pool
+
+	return pool, nil
+}
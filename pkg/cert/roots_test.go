@@ -0,0 +1,137 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCertPool(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	other := newTestCA(t, "Other Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "bundle.pem")
+	writeTestPEM(t, bundle, other, root)
+
+	caDir := filepath.Join(dir, "certs")
+	if err := os.Mkdir(caDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestPEM(t, filepath.Join(caDir, "root.crt"), root)
+	if err := os.WriteFile(filepath.Join(caDir, "README"), []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyDir := filepath.Join(dir, "empty")
+	if err := os.Mkdir(emptyDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		paths      []string
+		system     bool
+		wantNil    bool
+		wantErr    bool
+		wantVerify bool
+	}{
+		{name: "system roots only", system: true, wantNil: true},
+		{name: "no roots at all", wantVerify: false},
+		{name: "bundle file", paths: []string{bundle}, wantVerify: true},
+		{name: "bundle on top of system roots", paths: []string{bundle}, system: true, wantVerify: true},
+		{name: "directory", paths: []string{caDir}, wantVerify: true},
+		{name: "missing path", paths: []string{filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "file without certificates", paths: []string{garbage}, wantErr: true},
+		{name: "directory without certificates", paths: []string{emptyDir}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := LoadCertPool(tt.paths, tt.system)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadCertPool() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCertPool() unexpected error: %v", err)
+			}
+
+			if (pool == nil) != tt.wantNil {
+				t.Fatalf("LoadCertPool() pool = %v, wantNil %v", pool, tt.wantNil)
+			}
+			if pool == nil {
+				return
+			}
+
+			checker := NewWithOptions(Options{Roots: pool})
+			info := &CertificateInfo{}
+			checker.verifyCertificate(info, leaf.Cert, nil, checker.roots, "example.com", time.Now())
+			if info.Verified != tt.wantVerify {
+				t.Errorf("verifyCertificate() Verified = %v, want %v (error: %s)", info.Verified, tt.wantVerify, info.VerificationError)
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_PerHostCA(t *testing.T) {
+	internalRoot := newTestCA(t, "Internal Root", nil)
+	leaf := newTestLeaf(t, internalRoot, "example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	caFile := filepath.Join(t.TempDir(), "internal.pem")
+	writeTestPEM(t, caFile, internalRoot)
+
+	tests := []struct {
+		name         string
+		ca           string
+		wantVerified bool
+		wantStatus   Status
+		wantErr      bool
+	}{
+		{name: "default roots", wantStatus: StatusError},
+		{name: "per-host CA", ca: caFile, wantVerified: true, wantStatus: StatusOK},
+		{name: "missing per-host CA", ca: caFile + ".missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewWithOptions(Options{
+				Timeout:    5 * time.Second,
+				Thresholds: DefaultThresholds(),
+			})
+
+			target := newTestTarget(t, host, port)
+			target.CA = tt.ca
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("getCertInfoByHost() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.Verified != tt.wantVerified {
+				t.Errorf("getCertInfoByHost() Verified = %v, want %v (error: %s)", info.Verified, tt.wantVerified, info.VerificationError)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("getCertInfoByHost() Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
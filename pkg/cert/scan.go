@@ -0,0 +1,74 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"slices"
+)
+
+// scanVersions are the TLS versions probed by scanProtocols, newest first
+var scanVersions = []uint16{tls.VersionTLS13, tls.VersionTLS12, tls.VersionTLS11, tls.VersionTLS10}
+
+// scanProtocols probes target with every TLS version and, below TLS 1.3, every
+// cipher suite crypto/tls implements, one handshake per combination
+func (c *Checker) scanProtocols(ctx context.Context, target Target, clientCert *tls.Certificate, state *tls.ConnectionState) *ProtocolScan {
+	scan := &ProtocolScan{
+		NegotiatedVersion: tls.VersionName(state.Version),
+		NegotiatedCipher:  tls.CipherSuiteName(state.CipherSuite),
+	}
+
+	suites := append(tls.CipherSuites(), tls.InsecureCipherSuites()...)
+
+	for _, version := range scanVersions {
+		result := VersionScan{Version: tls.VersionName(version)}
+
+		negotiated, ok := c.probeHandshake(ctx, target, clientCert, version, nil)
+		if ok {
+			result.Supported = true
+
+			if version == tls.VersionTLS13 {
+				result.Ciphers = append(result.Ciphers, cipherScan(negotiated, suites))
+			} else {
+				for _, suite := range suites {
+					if !slices.Contains(suite.SupportedVersions, version) || ctx.Err() != nil {
+						continue
+					}
+					if _, ok := c.probeHandshake(ctx, target, clientCert, version, []uint16{suite.ID}); ok {
+						result.Ciphers = append(result.Ciphers, CipherScan{Name: suite.Name, Insecure: suite.Insecure})
+					}
+				}
+			}
+		}
+
+		scan.Versions = append(scan.Versions, result)
+	}
+
+	return scan
+}
+
+// probeHandshake attempts a handshake pinned to version and, when given, the
+// cipher suites, returning the negotiated suite on success
+func (c *Checker) probeHandshake(ctx context.Context, target Target, clientCert *tls.Certificate, version uint16, suites []uint16) (uint16, bool) {
+	tlsConfig := c.newTLSConfig(target, clientCert, &ClientAuthInfo{})
+	tlsConfig.MinVersion = version
+	tlsConfig.MaxVersion = version
+	tlsConfig.CipherSuites = suites
+
+	state, _, err := c.getConnectionState(ctx, target, tlsConfig)
+	if err != nil {
+		return 0, false
+	}
+
+	return state.CipherSuite, true
+}
+
+// cipherScan describes the suite with the given ID
+func cipherScan(id uint16, suites []*tls.CipherSuite) CipherScan {
+	for _, suite := range suites {
+		if suite.ID == id {
+			return CipherScan{Name: suite.Name, Insecure: suite.Insecure}
+		}
+	}
+
+	return CipherScan{Name: tls.CipherSuiteName(id)}
+}
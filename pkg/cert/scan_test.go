@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestScanProtocols(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+
+	gcm := tls.CipherSuiteName(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)
+	cbc := tls.CipherSuiteName(tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA)
+	rc4 := tls.CipherSuiteName(tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA)
+
+	tests := []struct {
+		name          string
+		server        *tls.Config
+		wantVersion   string
+		wantSupported map[string][]string
+		wantInsecure  string
+	}{
+		{
+			name: "legacy server",
+			server: &tls.Config{
+				MinVersion: tls.VersionTLS10,
+				MaxVersion: tls.VersionTLS12,
+				CipherSuites: []uint16{
+					tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+					tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+					tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+				},
+			},
+			wantVersion: "TLS 1.2",
+			wantSupported: map[string][]string{
+				"TLS 1.2": {gcm, cbc, rc4},
+				"TLS 1.1": {cbc, rc4},
+				"TLS 1.0": {cbc, rc4},
+			},
+			wantInsecure: rc4,
+		},
+		{
+			name:        "modern server",
+			server:      &tls.Config{MinVersion: tls.VersionTLS13},
+			wantVersion: "TLS 1.3",
+			wantSupported: map[string][]string{
+				"TLS 1.3": nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.Certificates = []tls.Certificate{leaf.tlsCertificate()}
+			host, port := startTestTLSServer(t, tt.server)
+
+			checker := NewWithOptions(Options{
+				Timeout:       5 * time.Second,
+				Insecure:      true,
+				Thresholds:    DefaultThresholds(),
+				ScanProtocols: true,
+			})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			scan := info.Protocols
+			if scan == nil {
+				t.Fatal("getCertInfoByHost() Protocols is nil")
+			}
+
+			if scan.NegotiatedVersion != tt.wantVersion {
+				t.Errorf("NegotiatedVersion = %q, want %q", scan.NegotiatedVersion, tt.wantVersion)
+			}
+
+			if len(scan.Versions) != len(scanVersions) {
+				t.Fatalf("Versions length = %d, want %d", len(scan.Versions), len(scanVersions))
+			}
+
+			for _, version := range scan.Versions {
+				wantCiphers, wantSupported := tt.wantSupported[version.Version]
+				if version.Supported != wantSupported {
+					t.Errorf("%s Supported = %v, want %v", version.Version, version.Supported, wantSupported)
+					continue
+				}
+
+				var names []string
+				for _, cipher := range version.Ciphers {
+					names = append(names, cipher.Name)
+					if cipher.Insecure != (cipher.Name == tt.wantInsecure) {
+						t.Errorf("%s cipher %s Insecure = %v", version.Version, cipher.Name, cipher.Insecure)
+					}
+				}
+
+				if version.Version == "TLS 1.3" {
+					if wantSupported && len(names) != 1 {
+						t.Errorf("TLS 1.3 Ciphers = %v, want the negotiated suite only", names)
+					}
+					continue
+				}
+
+				slices.Sort(names)
+				slices.Sort(wantCiphers)
+				if !slices.Equal(names, wantCiphers) {
+					t.Errorf("%s Ciphers = %v, want %v", version.Version, names, wantCiphers)
+				}
+			}
+		})
+	}
+}
+
+func TestScanProtocols_Disabled(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+	host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}})
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+	info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+	if err != nil {
+		t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+	}
+
+	if info.Protocols != nil {
+		t.Errorf("getCertInfoByHost() Protocols = %+v, want nil without --scan-protocols", info.Protocols)
+	}
+}
@@ -0,0 +1,24 @@
+package cert
+
+// ProtocolScan is the matrix of TLS versions and cipher suites a server accepts
+type ProtocolScan struct {
+	// NegotiatedVersion and NegotiatedCipher are what the default handshake settled on
+	NegotiatedVersion string        `json:"negotiated_version" yaml:"negotiated_version"`
+	NegotiatedCipher  string        `json:"negotiated_cipher" yaml:"negotiated_cipher"`
+	Versions          []VersionScan `json:"versions" yaml:"versions"`
+}
+
+// VersionScan records whether a TLS version is accepted and with which cipher suites
+type VersionScan struct {
+	Version   string `json:"version" yaml:"version"`
+	Supported bool   `json:"supported" yaml:"supported"`
+	// Ciphers are the accepted suites; for TLS 1.3, where the client cannot
+	// restrict suites, only the negotiated one is listed
+	Ciphers []CipherScan `json:"ciphers,omitempty" yaml:"ciphers,omitempty"`
+}
+
+// CipherScan is an accepted cipher suite
+type CipherScan struct {
+	Name     string `json:"name" yaml:"name"`
+	Insecure bool   `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+}
@@ -0,0 +1,218 @@
+package cert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// clientName identifies the checker in protocol greetings
+const clientName = "ssl-certs-checker"
+
+// maxNegotiationBytes bounds how much plaintext is read while waiting for a server reply
+const maxNegotiationBytes = 64 * 1024
+
+func (smtpNegotiator) Name() string     { return "smtp" }
+func (smtpNegotiator) DefaultPort() int { return 25 }
+
+func (smtpNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	r := bufio.NewReader(conn)
+
+	if err := expectReplyCode(r, "220"); err != nil {
+		return fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if err := writeLine(conn, "EHLO "+clientName); err != nil {
+		return err
+	}
+	if err := expectReplyCode(r, "250"); err != nil {
+		return fmt.Errorf("EHLO rejected: %w", err)
+	}
+
+	if err := writeLine(conn, "STARTTLS"); err != nil {
+		return err
+	}
+	if err := expectReplyCode(r, "220"); err != nil {
+		return fmt.Errorf("STARTTLS rejected: %w", err)
+	}
+
+	return nil
+}
+
+func (imapNegotiator) Name() string     { return "imap" }
+func (imapNegotiator) DefaultPort() int { return 143 }
+
+func (imapNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	r := bufio.NewReader(conn)
+
+	greeting, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected greeting: %s", greeting)
+	}
+
+	const tag = "a001"
+	if err := writeLine(conn, tag+" STARTTLS"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(line, tag+" ") {
+			continue
+		}
+		if strings.HasPrefix(line, tag+" OK") {
+			return nil
+		}
+
+		return fmt.Errorf("STARTTLS rejected: %s", line)
+	}
+}
+
+func (pop3Negotiator) Name() string     { return "pop3" }
+func (pop3Negotiator) DefaultPort() int { return 110 }
+
+func (pop3Negotiator) Negotiate(conn net.Conn, hostname string) error {
+	r := bufio.NewReader(conn)
+
+	greeting, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("unexpected greeting: %s", greeting)
+	}
+
+	if err := writeLine(conn, "STLS"); err != nil {
+		return err
+	}
+
+	reply, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reply, "+OK") {
+		return fmt.Errorf("STLS rejected: %s", reply)
+	}
+
+	return nil
+}
+
+func (ftpNegotiator) Name() string     { return "ftp" }
+func (ftpNegotiator) DefaultPort() int { return 21 }
+
+func (ftpNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	r := bufio.NewReader(conn)
+
+	if err := expectReplyCode(r, "220"); err != nil {
+		return fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if err := writeLine(conn, "AUTH TLS"); err != nil {
+		return err
+	}
+	if err := expectReplyCode(r, "234"); err != nil {
+		return fmt.Errorf("AUTH TLS rejected: %w", err)
+	}
+
+	return nil
+}
+
+func (xmppNegotiator) Name() string     { return "xmpp" }
+func (xmppNegotiator) DefaultPort() int { return 5222 }
+
+func (xmppNegotiator) Negotiate(conn net.Conn, hostname string) error {
+	r := bufio.NewReader(conn)
+
+	header := fmt.Sprintf("<?xml version='1.0'?><stream:stream to='%s' xmlns='jabber:client' "+
+		"xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>", hostname)
+	if _, err := io.WriteString(conn, header); err != nil {
+		return err
+	}
+
+	features, err := readUntil(r, "</stream:features>")
+	if err != nil {
+		return fmt.Errorf("reading stream features: %w", err)
+	}
+	if !strings.Contains(features, "urn:ietf:params:xml:ns:xmpp-tls") {
+		return fmt.Errorf("server does not offer STARTTLS")
+	}
+
+	if _, err := io.WriteString(conn, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"); err != nil {
+		return err
+	}
+
+	reply, err := readUntil(r, "/>")
+	if err != nil {
+		return fmt.Errorf("reading STARTTLS reply: %w", err)
+	}
+	if !strings.Contains(reply, "<proceed") {
+		return fmt.Errorf("STARTTLS rejected: %s", strings.TrimSpace(reply))
+	}
+
+	return nil
+}
+
+// expectReplyCode reads a (possibly multi-line) SMTP/FTP style reply and checks its code
+func expectReplyCode(r *bufio.Reader, code string) error {
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+
+		if len(line) < 3 || !strings.HasPrefix(line, code) {
+			return fmt.Errorf("expected %s, got: %s", code, line)
+		}
+
+		// "250-..." continues a multi-line reply, "250 ..." or a bare "250" ends it
+		if len(line) == 3 || line[3] != '-' {
+			return nil
+		}
+	}
+}
+
+// readLine reads a single CRLF or LF terminated line
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("reading server reply: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeLine writes a CRLF terminated command
+func writeLine(w io.Writer, line string) error {
+	if _, err := io.WriteString(w, line+"\r\n"); err != nil {
+		return fmt.Errorf("sending %q: %w", strings.Fields(line)[0], err)
+	}
+
+	return nil
+}
+
+// readUntil reads until marker has been seen and returns everything read
+func readUntil(r *bufio.Reader, marker string) (string, error) {
+	var sb strings.Builder
+	for sb.Len() < maxNegotiationBytes {
+		b, err := r.ReadByte()
+		if err != nil {
+			return sb.String(), err
+		}
+
+		sb.WriteByte(b)
+		if strings.HasSuffix(sb.String(), marker) {
+			return sb.String(), nil
+		}
+	}
+
+	return sb.String(), fmt.Errorf("no %q within %d bytes", marker, maxNegotiationBytes)
+}
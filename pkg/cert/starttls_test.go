@@ -0,0 +1,179 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expectClientLine reads a line from the client and checks it starts with prefix
+func expectClientLine(r *bufio.Reader, prefix string) error {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, prefix) {
+		return fmt.Errorf("unexpected client line: %q", line)
+	}
+
+	return nil
+}
+
+func fakeSMTPServer(conn net.Conn, r *bufio.Reader) error {
+	io.WriteString(conn, "220-mail.example.com ESMTP\r\n220 ready\r\n")
+	if err := expectClientLine(r, "EHLO "); err != nil {
+		return err
+	}
+	io.WriteString(conn, "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+	if err := expectClientLine(r, "STARTTLS"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "220 Ready to start TLS\r\n")
+	return nil
+}
+
+func fakeIMAPServer(conn net.Conn, r *bufio.Reader) error {
+	io.WriteString(conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS] ready\r\n")
+	if err := expectClientLine(r, "a001 STARTTLS"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "a001 OK Begin TLS negotiation now\r\n")
+	return nil
+}
+
+func fakePOP3Server(conn net.Conn, r *bufio.Reader) error {
+	io.WriteString(conn, "+OK POP3 ready\r\n")
+	if err := expectClientLine(r, "STLS"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "+OK Begin TLS negotiation\r\n")
+	return nil
+}
+
+func fakeFTPServer(conn net.Conn, r *bufio.Reader) error {
+	io.WriteString(conn, "220 FTP server ready\r\n")
+	if err := expectClientLine(r, "AUTH TLS"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "234 AUTH TLS successful\r\n")
+	return nil
+}
+
+func fakeXMPPServer(conn net.Conn, r *bufio.Reader) error {
+	if _, err := readUntil(r, "version='1.0'>"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "<?xml version='1.0'?><stream:stream from='example.com' id='1' version='1.0' "+
+		"xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams'>"+
+		"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls></stream:features>")
+	if _, err := readUntil(r, "/>"); err != nil {
+		return err
+	}
+	io.WriteString(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+	return nil
+}
+
+func TestNegotiators_FakeServers(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "mail.example.com")
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{leaf.tlsCertificate()}}
+
+	tests := []struct {
+		scheme string
+		server func(conn net.Conn, r *bufio.Reader) error
+	}{
+		{scheme: "smtp", server: fakeSMTPServer},
+		{scheme: "imap", server: fakeIMAPServer},
+		{scheme: "pop3", server: fakePOP3Server},
+		{scheme: "ftp", server: fakeFTPServer},
+		{scheme: "xmpp", server: fakeXMPPServer},
+	}
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			host, port := startTestUpgradeServer(t, tlsConfig, tt.server)
+
+			target, err := parseTarget(fmt.Sprintf("%s://%s:%d", tt.scheme, host, port), "")
+			if err != nil {
+				t.Fatalf("parseTarget() unexpected error: %v", err)
+			}
+
+			info, err := checker.getCertInfoByHost(context.Background(), target)
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.CommonName != "mail.example.com" {
+				t.Errorf("getCertInfoByHost() CommonName = %q, want mail.example.com", info.CommonName)
+			}
+
+			wantHost := fmt.Sprintf("%s://%s:%d", tt.scheme, host, port)
+			if info.Host != wantHost {
+				t.Errorf("getCertInfoByHost() Host = %q, want %q", info.Host, wantHost)
+			}
+		})
+	}
+}
+
+func TestNegotiators_Rejected(t *testing.T) {
+	host, port := startTestUpgradeServer(t, &tls.Config{}, func(conn net.Conn, r *bufio.Reader) error {
+		io.WriteString(conn, "220 ready\r\n")
+		if err := expectClientLine(r, "EHLO "); err != nil {
+			return err
+		}
+		io.WriteString(conn, "250 mail.example.com\r\n")
+		if err := expectClientLine(r, "STARTTLS"); err != nil {
+			return err
+		}
+		io.WriteString(conn, "454 TLS not available\r\n")
+		return fmt.Errorf("rejected")
+	})
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, StartTLS: "smtp"})
+	result, err := checker.CheckCertificates(context.Background(), []string{fmt.Sprintf("%s:%d", host, port)})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("CheckCertificates() errors count = %d, want 1", len(result.Errors))
+	}
+
+	if !strings.Contains(result.Errors[0].Error, "smtp negotiation failed") {
+		t.Errorf("CheckCertificates() error = %q, want smtp negotiation failure", result.Errors[0].Error)
+	}
+}
+
+func TestExpectReplyCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		code    string
+		wantErr bool
+	}{
+		{name: "single line", reply: "250 ok\r\n", code: "250"},
+		{name: "bare code", reply: "220\r\n", code: "220"},
+		{name: "multi-line", reply: "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n", code: "250"},
+		{name: "multi-line ending with bare code", reply: "250-mail.example.com\r\n250\r\n", code: "250"},
+		{name: "other code", reply: "454 TLS not available\r\n", code: "220", wantErr: true},
+		{name: "truncated code", reply: "25\r\n", code: "250", wantErr: true},
+		{name: "unterminated multi-line", reply: "250-mail.example.com\r\n", code: "250", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expectReplyCode(bufio.NewReader(strings.NewReader(tt.reply)), tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expectReplyCode(%q) error = %v, wantErr %v", tt.reply, err, tt.wantErr)
+			}
+		})
+	}
+}
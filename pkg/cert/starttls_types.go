@@ -0,0 +1,16 @@
+package cert
+
+// smtpNegotiator upgrades SMTP sessions with STARTTLS (RFC 3207)
+type smtpNegotiator struct{}
+
+// imapNegotiator upgrades IMAP sessions with STARTTLS (RFC 3501)
+type imapNegotiator struct{}
+
+// pop3Negotiator upgrades POP3 sessions with STLS (RFC 2595)
+type pop3Negotiator struct{}
+
+// ftpNegotiator upgrades FTP control connections with AUTH TLS (RFC 4217)
+type ftpNegotiator struct{}
+
+// xmppNegotiator upgrades XMPP client streams with STARTTLS (RFC 6120)
+type xmppNegotiator struct{}
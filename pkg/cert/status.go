@@ -0,0 +1,111 @@
+package cert
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	StatusOK       Status = "OK"
+	StatusWarning  Status = "WARNING"
+	StatusCritical Status = "CRITICAL"
+	StatusExpired  Status = "EXPIRED"
+	StatusError    Status = "ERROR"
+	StatusRevoked  Status = "REVOKED"
+)
+
+const (
+	DefaultWarnDays = 30
+	DefaultCritDays = 7
+)
+
+// DefaultThresholds returns the thresholds used when none are configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		WarnDays: DefaultWarnDays,
+		CritDays: DefaultCritDays,
+	}
+}
+
+// Evaluate fills in DaysRemaining and Status for the certificate relative to now
+func (t Thresholds) Evaluate(info *CertificateInfo, now time.Time) {
+	info.DaysRemaining = daysRemaining(info.NotAfter, now)
+
+	switch {
+	case now.After(info.NotAfter):
+		info.Status = StatusExpired
+	case t.CritDays > 0 && info.DaysRemaining < t.CritDays:
+		info.Status = StatusCritical
+	case t.WarnDays > 0 && info.DaysRemaining < t.WarnDays:
+		info.Status = StatusWarning
+	default:
+		info.Status = StatusOK
+	}
+}
+
+// daysRemaining returns the number of whole days until notAfter, negative once expired
+func daysRemaining(notAfter, now time.Time) int {
+	return int(math.Floor(notAfter.Sub(now).Hours() / 24))
+}
+
+// severity ranks statuses from healthy to failing
+func (s Status) severity() int {
+	switch s {
+	case StatusOK:
+		return 1
+	case StatusWarning:
+		return 2
+	case StatusCritical:
+		return 3
+	case StatusExpired:
+		return 4
+	case StatusError:
+		return 5
+	case StatusRevoked:
+		return 6
+	default:
+		return 0
+	}
+}
+
+// Worse returns whichever of the two statuses is more severe
+func (s Status) Worse(other Status) Status {
+	if other.severity() > s.severity() {
+		return other
+	}
+	return s
+}
+
+// ExitCode maps the status to a process exit code.
+// Exit code 1 is left for configuration and runtime failures.
+func (s Status) ExitCode() int {
+	switch s {
+	case StatusWarning:
+		return 2
+	case StatusCritical:
+		return 3
+	case StatusExpired:
+		return 4
+	case StatusError:
+		return 5
+	case StatusRevoked:
+		return 6
+	default:
+		return 0
+	}
+}
+
+// WorstStatus returns the most severe status across the result.
+// Hosts that could not be checked count as ERROR.
+func (r *Result) WorstStatus() Status {
+	status := StatusOK
+	for _, certInfo := range r.Certificates {
+		status = status.Worse(certInfo.Status)
+	}
+
+	if len(r.Errors) > 0 {
+		status = status.Worse(StatusError)
+	}
+
+	return status
+}
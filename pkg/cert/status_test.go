@@ -0,0 +1,151 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThresholds_Evaluate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		notAfter   time.Time
+		wantDays   int
+		wantStatus Status
+	}{
+		{
+			name:       "well within validity",
+			thresholds: DefaultThresholds(),
+			notAfter:   now.AddDate(0, 0, 90),
+			wantDays:   90,
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "inside warning window",
+			thresholds: DefaultThresholds(),
+			notAfter:   now.AddDate(0, 0, 20),
+			wantDays:   20,
+			wantStatus: StatusWarning,
+		},
+		{
+			name:       "inside critical window",
+			thresholds: DefaultThresholds(),
+			notAfter:   now.AddDate(0, 0, 3),
+			wantDays:   3,
+			wantStatus: StatusCritical,
+		},
+		{
+			name:       "expires later today",
+			thresholds: DefaultThresholds(),
+			notAfter:   now.Add(2 * time.Hour),
+			wantDays:   0,
+			wantStatus: StatusCritical,
+		},
+		{
+			name:       "already expired",
+			thresholds: DefaultThresholds(),
+			notAfter:   now.Add(-36 * time.Hour),
+			wantDays:   -2,
+			wantStatus: StatusExpired,
+		},
+		{
+			name:       "thresholds disabled",
+			thresholds: Thresholds{},
+			notAfter:   now.AddDate(0, 0, 1),
+			wantDays:   1,
+			wantStatus: StatusOK,
+		},
+		{
+			name:       "thresholds disabled still reports expired",
+			thresholds: Thresholds{},
+			notAfter:   now.Add(-time.Minute),
+			wantDays:   -1,
+			wantStatus: StatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &CertificateInfo{NotAfter: tt.notAfter}
+			tt.thresholds.Evaluate(info, now)
+
+			if info.DaysRemaining != tt.wantDays {
+				t.Errorf("Evaluate() DaysRemaining = %d, want %d", info.DaysRemaining, tt.wantDays)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("Evaluate() Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestStatus_ExitCode(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   int
+	}{
+		{StatusOK, 0},
+		{StatusWarning, 2},
+		{StatusCritical, 3},
+		{StatusExpired, 4},
+		{StatusError, 5},
+		{StatusRevoked, 6},
+		{Status(""), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.ExitCode(); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResult_WorstStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   Status
+	}{
+		{
+			name:   "empty result",
+			result: Result{},
+			want:   StatusOK,
+		},
+		{
+			name: "mixed certificates",
+			result: Result{
+				Certificates: []CertificateInfo{
+					{Status: StatusOK},
+					{Status: StatusCritical},
+					{Status: StatusWarning},
+				},
+			},
+			want: StatusCritical,
+		},
+		{
+			name: "host errors outrank expiry",
+			result: Result{
+				Certificates: []CertificateInfo{
+					{Status: StatusExpired},
+				},
+				Errors: []ErrorInfo{
+					{Host: "invalid.com:443", Error: "connection failed"},
+				},
+			},
+			want: StatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.WorstStatus(); got != tt.want {
+				t.Errorf("WorstStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package cert
+
+// Status summarizes the health of a checked certificate
+type Status string
+
+// Thresholds controls when a certificate is reported as WARNING or CRITICAL.
+// A value of 0 disables the corresponding level.
+type Thresholds struct {
+	WarnDays int
+	CritDays int
+}
@@ -0,0 +1,153 @@
+package cert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitScheme splits an optional "scheme://" prefix from a host string
+func SplitScheme(hostStr string) (scheme, rest string) {
+	if idx := strings.Index(hostStr, "://"); idx >= 0 {
+		return strings.ToLower(strings.TrimSpace(hostStr[:idx])), hostStr[idx+3:]
+	}
+
+	return "", hostStr
+}
+
+// SplitServerName splits an optional "sni@" prefix from a host string.
+// hasServerName is true when an "@" was present, even if the name before it is empty.
+func SplitServerName(hostStr string) (serverName, rest string, hasServerName bool) {
+	if idx := strings.LastIndex(hostStr, "@"); idx >= 0 {
+		return strings.TrimSpace(hostStr[:idx]), hostStr[idx+1:], true
+	}
+
+	return "", hostStr, false
+}
+
+// parseTarget parses a host string with an optional scheme into a Target.
+// defaultScheme is used when the string has no scheme; empty means https.
+func parseTarget(hostStr, defaultScheme string) (Target, error) {
+	scheme, rest := SplitScheme(strings.TrimSpace(hostStr))
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	if scheme == "" {
+		scheme = DefaultScheme
+	}
+
+	negotiator, ok := LookupNegotiator(scheme)
+	if !ok {
+		return Target{}, fmt.Errorf("unsupported protocol: %s (supported: %s)", scheme, strings.Join(SupportedSchemes(), ", "))
+	}
+
+	serverName, rest, hasServerName := SplitServerName(rest)
+
+	hostname, port, err := parseHostWithDefaultPort(rest, negotiator.DefaultPort())
+	if err != nil {
+		return Target{}, err
+	}
+
+	return Target{
+		Hostname:   hostname,
+		Port:       port,
+		ServerName: serverName,
+		NoSNI:      hasServerName && serverName == "",
+		Negotiator: negotiator,
+	}, nil
+}
+
+// resolveTarget applies the per-host overrides of a HostSpec on top of its host string
+func resolveTarget(spec HostSpec, defaultScheme string) (Target, error) {
+	hostStr := spec.Host
+	if strings.TrimSpace(hostStr) == "" {
+		hostStr = spec.ConnectTo
+	}
+
+	target, err := parseTarget(hostStr, defaultScheme)
+	if err != nil {
+		return Target{}, err
+	}
+
+	if spec.ConnectTo != "" && spec.Host != "" {
+		if target.ServerName == "" && !target.NoSNI {
+			target.ServerName = target.Hostname
+		}
+
+		hostname, port, err := parseHostWithDefaultPort(spec.ConnectTo, target.Port)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid connect_to address: %w", err)
+		}
+		target.Hostname = hostname
+		target.Port = port
+	}
+
+	if spec.SNI != "" {
+		target.ServerName = spec.SNI
+		target.NoSNI = false
+	}
+
+	if spec.NoSNI {
+		target.ServerName = ""
+		target.NoSNI = true
+	}
+
+	target.CA = spec.CA
+	target.ClientCert = spec.ClientCert
+	target.ExpectedNames = spec.ExpectedNames
+	target.Pins = spec.Pins
+
+	return target, nil
+}
+
+// VerifyName returns the name the presented certificate is expected to cover
+func (t Target) VerifyName() string {
+	if t.ServerName != "" {
+		return t.ServerName
+	}
+
+	return t.Hostname
+}
+
+// CoverageNames returns the names the certificate's SANs must cover
+func (t Target) CoverageNames() []string {
+	if len(t.ExpectedNames) > 0 {
+		return t.ExpectedNames
+	}
+
+	return []string{t.VerifyName()}
+}
+
+// SNI returns the server name sent in the ClientHello, empty when none is sent
+func (t Target) SNI() string {
+	if t.NoSNI {
+		return ""
+	}
+
+	return t.VerifyName()
+}
+
+// String returns the label used to report the target.
+// Plain HTTPS targets keep the historical "host:port" form.
+func (t Target) String() string {
+	address := fmt.Sprintf("%s:%d", t.Hostname, t.Port)
+	if t.NoSNI {
+		address = "@" + address
+	} else if t.ServerName != "" && t.ServerName != t.Hostname {
+		address = t.ServerName + "@" + address
+	}
+
+	if t.Negotiator == nil || t.Negotiator.Name() == DefaultScheme {
+		return address
+	}
+
+	return t.Negotiator.Name() + "://" + address
+}
+
+// label returns a printable name for a HostSpec that could not be resolved
+func (s HostSpec) label() string {
+	if s.Host != "" {
+		return s.Host
+	}
+
+	return s.ConnectTo
+}
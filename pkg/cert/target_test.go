@@ -0,0 +1,284 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultScheme string
+		wantScheme    string
+		wantHostname  string
+		wantPort      int
+		wantLabel     string
+		wantErr       bool
+	}{
+		{
+			name:         "plain host keeps https",
+			input:        "example.com",
+			wantScheme:   "https",
+			wantHostname: "example.com",
+			wantPort:     443,
+			wantLabel:    "example.com:443",
+		},
+		{
+			name:         "smtp with explicit port",
+			input:        "smtp://mail.example.com:587",
+			wantScheme:   "smtp",
+			wantHostname: "mail.example.com",
+			wantPort:     587,
+			wantLabel:    "smtp://mail.example.com:587",
+		},
+		{
+			name:         "scheme default port",
+			input:        "imap://mail.example.com",
+			wantScheme:   "imap",
+			wantHostname: "mail.example.com",
+			wantPort:     143,
+			wantLabel:    "imap://mail.example.com:143",
+		},
+		{
+			name:          "default scheme from option",
+			input:         "ftp.example.com",
+			defaultScheme: "ftp",
+			wantScheme:    "ftp",
+			wantHostname:  "ftp.example.com",
+			wantPort:      21,
+			wantLabel:     "ftp://ftp.example.com:21",
+		},
+		{
+			name:          "explicit scheme overrides option",
+			input:         "https://example.com",
+			defaultScheme: "smtp",
+			wantScheme:    "https",
+			wantHostname:  "example.com",
+			wantPort:      443,
+			wantLabel:     "example.com:443",
+		},
+		{
+			name:         "scheme with IPv6",
+			input:        "xmpp://[::1]:5223",
+			wantScheme:   "xmpp",
+			wantHostname: "::1",
+			wantPort:     5223,
+			wantLabel:    "xmpp://::1:5223",
+		},
+		{
+			name:         "server name before address",
+			input:        "example.com@10.0.0.5:8443",
+			wantScheme:   "https",
+			wantHostname: "10.0.0.5",
+			wantPort:     8443,
+			wantLabel:    "example.com@10.0.0.5:8443",
+		},
+		{
+			name:         "empty server name disables SNI",
+			input:        "@10.0.0.5",
+			wantScheme:   "https",
+			wantHostname: "10.0.0.5",
+			wantPort:     443,
+			wantLabel:    "@10.0.0.5:443",
+		},
+		{
+			name:         "scheme with server name",
+			input:        "smtp://mail.example.com@10.0.0.7:587",
+			wantScheme:   "smtp",
+			wantHostname: "10.0.0.7",
+			wantPort:     587,
+			wantLabel:    "smtp://mail.example.com@10.0.0.7:587",
+		},
+		{
+			name:    "unknown scheme",
+			input:   "gopher://example.com",
+			wantErr: true,
+		},
+		{
+			name:    "scheme without host",
+			input:   "smtp://",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := parseTarget(tt.input, tt.defaultScheme)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTarget() expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseTarget() unexpected error: %v", err)
+			}
+
+			if target.Negotiator.Name() != tt.wantScheme {
+				t.Errorf("parseTarget() scheme = %v, want %v", target.Negotiator.Name(), tt.wantScheme)
+			}
+
+			if target.Hostname != tt.wantHostname || target.Port != tt.wantPort {
+				t.Errorf("parseTarget() = %s:%d, want %s:%d", target.Hostname, target.Port, tt.wantHostname, tt.wantPort)
+			}
+
+			if target.String() != tt.wantLabel {
+				t.Errorf("Target.String() = %q, want %q", target.String(), tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           HostSpec
+		wantHostname   string
+		wantPort       int
+		wantSNI        string
+		wantVerifyName string
+		wantErr        bool
+	}{
+		{
+			name:           "plain host",
+			spec:           HostSpec{Host: "example.com"},
+			wantHostname:   "example.com",
+			wantPort:       443,
+			wantSNI:        "example.com",
+			wantVerifyName: "example.com",
+		},
+		{
+			name:           "connect_to keeps host as server name",
+			spec:           HostSpec{Host: "example.com:8443", ConnectTo: "10.0.0.5"},
+			wantHostname:   "10.0.0.5",
+			wantPort:       8443,
+			wantSNI:        "example.com",
+			wantVerifyName: "example.com",
+		},
+		{
+			name:           "connect_to with its own port",
+			spec:           HostSpec{Host: "example.com", ConnectTo: "10.0.0.5:9443"},
+			wantHostname:   "10.0.0.5",
+			wantPort:       9443,
+			wantSNI:        "example.com",
+			wantVerifyName: "example.com",
+		},
+		{
+			name:           "sni override",
+			spec:           HostSpec{ConnectTo: "10.0.0.5:443", SNI: "www.example.com"},
+			wantHostname:   "10.0.0.5",
+			wantPort:       443,
+			wantSNI:        "www.example.com",
+			wantVerifyName: "www.example.com",
+		},
+		{
+			name:           "no sni",
+			spec:           HostSpec{Host: "example.com", ConnectTo: "10.0.0.5", NoSNI: true},
+			wantHostname:   "10.0.0.5",
+			wantPort:       443,
+			wantSNI:        "",
+			wantVerifyName: "10.0.0.5",
+		},
+		{
+			name:    "invalid connect_to",
+			spec:    HostSpec{Host: "example.com", ConnectTo: "10.0.0.5:abc"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := resolveTarget(tt.spec, "")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveTarget() expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveTarget() unexpected error: %v", err)
+			}
+
+			if target.Hostname != tt.wantHostname || target.Port != tt.wantPort {
+				t.Errorf("resolveTarget() dial = %s:%d, want %s:%d", target.Hostname, target.Port, tt.wantHostname, tt.wantPort)
+			}
+
+			if target.SNI() != tt.wantSNI {
+				t.Errorf("Target.SNI() = %q, want %q", target.SNI(), tt.wantSNI)
+			}
+
+			if target.VerifyName() != tt.wantVerifyName {
+				t.Errorf("Target.VerifyName() = %q, want %q", target.VerifyName(), tt.wantVerifyName)
+			}
+		})
+	}
+}
+
+func TestCheckHosts_SNISelection(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	named := newTestLeaf(t, root, "www.example.com")
+	fallback := newTestLeaf(t, root, "default.example.com")
+
+	host, port := startTestTLSServer(t, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			certificate := fallback.tlsCertificate()
+			if hello.ServerName == "www.example.com" {
+				certificate = named.tlsCertificate()
+			}
+			return &certificate, nil
+		},
+	})
+
+	checker := NewWithOptions(Options{Timeout: 5 * time.Second, Insecure: true})
+
+	tests := []struct {
+		name   string
+		host   string
+		wantCN string
+	}{
+		{name: "sni from host string", host: fmt.Sprintf("www.example.com@%s:%d", host, port), wantCN: "www.example.com"},
+		{name: "no sni", host: fmt.Sprintf("@%s:%d", host, port), wantCN: "default.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := checker.CheckCertificates(context.Background(), []string{tt.host})
+			if err != nil {
+				t.Fatalf("CheckCertificates() unexpected error: %v", err)
+			}
+
+			if len(result.Certificates) != 1 {
+				t.Fatalf("CheckCertificates() certificates = %d, errors = %v", len(result.Certificates), result.Errors)
+			}
+
+			if got := result.Certificates[0].CommonName; got != tt.wantCN {
+				t.Errorf("CheckCertificates() CommonName = %q, want %q", got, tt.wantCN)
+			}
+
+			if got := result.Certificates[0].Host; got != tt.host {
+				t.Errorf("CheckCertificates() Host = %q, want %q", got, tt.host)
+			}
+		})
+	}
+}
+
+func TestTarget_CoverageNames(t *testing.T) {
+	target := Target{Hostname: "10.0.0.5", ServerName: "example.com"}
+	if got := target.CoverageNames(); !reflect.DeepEqual(got, []string{"example.com"}) {
+		t.Errorf("CoverageNames() = %v, want [example.com]", got)
+	}
+
+	target.ExpectedNames = []string{"a.example.com", "b.example.com"}
+	if got := target.CoverageNames(); !reflect.DeepEqual(got, target.ExpectedNames) {
+		t.Errorf("CoverageNames() = %v, want %v", got, target.ExpectedNames)
+	}
+}
@@ -0,0 +1,37 @@
+package cert
+
+// HostSpec is a host entry together with its per-host connection settings
+type HostSpec struct {
+	// Host is "[scheme://][sni@]address[:port]"; it may be empty when ConnectTo is set
+	Host string
+	// ConnectTo overrides the address that is dialed, keeping Host as the server name
+	ConnectTo string
+	// SNI overrides the server name sent in the handshake and used for verification
+	SNI string
+	// NoSNI sends no server name at all, to see the server's default certificate
+	NoSNI bool
+	// CA is a PEM file or directory whose certificates replace the default roots for this host
+	CA string
+	// ClientCert overrides the checker's client certificate for this host
+	ClientCert ClientCertSpec
+	// ExpectedNames must all be covered by the certificate; empty means the verified name
+	ExpectedNames []string
+	// Pins are certificate or SPKI SHA-256 hashes, one of which the presented chain must match
+	Pins []string
+}
+
+// Target is a fully resolved host entry
+type Target struct {
+	// Hostname and Port are the address that is dialed
+	Hostname string
+	Port     int
+	// ServerName is sent as SNI and used for verification; empty means Hostname
+	ServerName string
+	NoSNI      bool
+	Negotiator Negotiator
+	// CA and ClientCert override the checker's roots and client certificate; see HostSpec
+	CA            string
+	ClientCert    ClientCertSpec
+	ExpectedNames []string
+	Pins          []string
+}
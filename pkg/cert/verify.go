@@ -0,0 +1,92 @@
+package cert
+
+import (
+	"crypto/x509"
+	"errors"
+	"time"
+)
+
+const (
+	VerifyFailureExpired           VerifyFailure = "expired"
+	VerifyFailureNotYetValid       VerifyFailure = "not_yet_valid"
+	VerifyFailureUnknownAuthority  VerifyFailure = "unknown_authority"
+	VerifyFailureHostnameMismatch  VerifyFailure = "hostname_mismatch"
+	VerifyFailureIncompatibleUsage VerifyFailure = "incompatible_usage"
+	VerifyFailurePinMismatch       VerifyFailure = "pin_mismatch"
+	VerifyFailureOther             VerifyFailure = "other"
+)
+
+// verifyCertificate verifies the leaf against roots using the remaining
+// presented certificates as intermediates, and records the outcome on info.
+// The leaf must be valid for usages, or for server authentication when none are given.
+func (c *Checker) verifyCertificate(info *CertificateInfo, leaf *x509.Certificate, certs []*x509.Certificate, roots *x509.CertPool, hostname string, now time.Time, usages ...x509.ExtKeyUsage) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs {
+		if cert == nil || cert == leaf {
+			continue
+		}
+		intermediates.AddCert(cert)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       hostname,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     usages,
+	})
+	if err == nil {
+		info.Verified = true
+		return
+	}
+
+	info.Verified = false
+	info.VerificationFailure = classifyVerifyError(err, leaf, now)
+	info.VerificationError = err.Error()
+}
+
+// untrusted reports whether verification failed for a reason other than the
+// leaf's own expiry, which the thresholds already report as EXPIRED. An expired
+// intermediate leaves a valid leaf untrusted.
+func untrusted(info *CertificateInfo, leaf *x509.Certificate, now time.Time) bool {
+	if info.Verified {
+		return false
+	}
+
+	return info.VerificationFailure != VerifyFailureExpired || !now.After(leaf.NotAfter)
+}
+
+// classifyVerifyError maps an x509 verification error to a VerifyFailure category
+func classifyVerifyError(err error, leaf *x509.Certificate, now time.Time) VerifyFailure {
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		switch invalidErr.Reason {
+		case x509.Expired:
+			// x509 uses the same reason for both ends of the validity window
+			cert := invalidErr.Cert
+			if cert == nil {
+				cert = leaf
+			}
+			if now.Before(cert.NotBefore) {
+				return VerifyFailureNotYetValid
+			}
+			return VerifyFailureExpired
+		case x509.IncompatibleUsage:
+			return VerifyFailureIncompatibleUsage
+		default:
+			return VerifyFailureOther
+		}
+	}
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthorityErr) {
+		return VerifyFailureUnknownAuthority
+	}
+
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return VerifyFailureHostnameMismatch
+	}
+
+	return VerifyFailureOther
+}
@@ -0,0 +1,193 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestVerifyCertificate(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	otherRoot := newTestCA(t, "Other Root", nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.Cert)
+
+	valid := newTestLeaf(t, intermediate, "example.com")
+	expired := issueTestCert(t, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		DNSNames:  []string{"example.com"},
+		NotBefore: time.Now().AddDate(0, 0, -60),
+		NotAfter:  time.Now().AddDate(0, 0, -1),
+	}, intermediate)
+	notYetValid := issueTestCert(t, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		DNSNames:  []string{"example.com"},
+		NotBefore: time.Now().AddDate(0, 0, 1),
+		NotAfter:  time.Now().AddDate(0, 0, 60),
+	}, intermediate)
+	clientOnly := issueTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		DNSNames:    []string{"example.com"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, intermediate)
+	untrusted := newTestLeaf(t, otherRoot, "example.com")
+
+	tests := []struct {
+		name         string
+		leaf         *testCert
+		hostname     string
+		wantVerified bool
+		wantFailure  VerifyFailure
+	}{
+		{name: "valid chain", leaf: valid, hostname: "example.com", wantVerified: true},
+		{name: "expired", leaf: expired, hostname: "example.com", wantFailure: VerifyFailureExpired},
+		{name: "not yet valid", leaf: notYetValid, hostname: "example.com", wantFailure: VerifyFailureNotYetValid},
+		{name: "unknown authority", leaf: untrusted, hostname: "example.com", wantFailure: VerifyFailureUnknownAuthority},
+		{name: "hostname mismatch", leaf: valid, hostname: "other.example.org", wantFailure: VerifyFailureHostnameMismatch},
+		{name: "incompatible usage", leaf: clientOnly, hostname: "example.com", wantFailure: VerifyFailureIncompatibleUsage},
+	}
+
+	checker := NewWithOptions(Options{Timeout: time.Second, Roots: roots})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &CertificateInfo{}
+			certs := []*x509.Certificate{tt.leaf.Cert, intermediate.Cert}
+			checker.verifyCertificate(info, tt.leaf.Cert, certs, checker.roots, tt.hostname, time.Now())
+
+			if info.Verified != tt.wantVerified {
+				t.Errorf("verifyCertificate() Verified = %v, want %v (error: %s)", info.Verified, tt.wantVerified, info.VerificationError)
+			}
+
+			if info.VerificationFailure != tt.wantFailure {
+				t.Errorf("verifyCertificate() VerificationFailure = %q, want %q", info.VerificationFailure, tt.wantFailure)
+			}
+
+			if !tt.wantVerified && info.VerificationError == "" {
+				t.Error("verifyCertificate() should record the verification error")
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_UntrustedChainStillReported(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	leaf := newTestLeaf(t, root, "example.com")
+
+	host, port := startTestTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{leaf.tlsCertificate()},
+	})
+
+	tests := []struct {
+		name       string
+		insecure   bool
+		wantStatus Status
+	}{
+		{name: "secure mode", insecure: false, wantStatus: StatusError},
+		{name: "insecure mode", insecure: true, wantStatus: StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewWithOptions(Options{
+				Timeout:    5 * time.Second,
+				Insecure:   tt.insecure,
+				Thresholds: DefaultThresholds(),
+			})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.CommonName != "example.com" {
+				t.Errorf("getCertInfoByHost() CommonName = %q, want example.com", info.CommonName)
+			}
+
+			if info.Verified {
+				t.Error("getCertInfoByHost() Verified = true for untrusted chain")
+			}
+
+			if info.VerificationFailure != VerifyFailureUnknownAuthority {
+				t.Errorf("getCertInfoByHost() VerificationFailure = %q, want %q", info.VerificationFailure, VerifyFailureUnknownAuthority)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("getCertInfoByHost() Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGetCertInfoByHost_ExpiredChain(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	roots := x509.NewCertPool()
+	roots.AddCert(root.Cert)
+
+	expiredIntermediate := issueTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Expired Intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		NotBefore:             time.Now().AddDate(0, 0, -30),
+		NotAfter:              time.Now().AddDate(0, 0, -1),
+	}, root)
+	intermediate := newTestCA(t, "Test Intermediate", root)
+	expiredLeaf := issueTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		DNSNames:    []string{"example.com"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		NotBefore:   time.Now().AddDate(0, 0, -30),
+		NotAfter:    time.Now().AddDate(0, 0, -1),
+	}, intermediate)
+
+	tests := []struct {
+		name        string
+		certificate tls.Certificate
+		wantStatus  Status
+	}{
+		{
+			// The leaf is fine, but the chain cannot be trusted
+			name:        "expired intermediate",
+			certificate: newTestLeaf(t, expiredIntermediate, "example.com").tlsCertificate(expiredIntermediate),
+			wantStatus:  StatusError,
+		},
+		{
+			name:        "expired leaf",
+			certificate: expiredLeaf.tlsCertificate(intermediate),
+			wantStatus:  StatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := startTestTLSServer(t, &tls.Config{Certificates: []tls.Certificate{tt.certificate}})
+
+			checker := NewWithOptions(Options{
+				Timeout:    5 * time.Second,
+				Thresholds: DefaultThresholds(),
+				Roots:      roots,
+			})
+
+			info, err := checker.getCertInfoByHost(context.Background(), newTestTarget(t, host, port))
+			if err != nil {
+				t.Fatalf("getCertInfoByHost() unexpected error: %v", err)
+			}
+
+			if info.Verified || info.VerificationFailure != VerifyFailureExpired {
+				t.Errorf("getCertInfoByHost() Verified = %v, VerificationFailure = %q, want an expired chain", info.Verified, info.VerificationFailure)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("getCertInfoByHost() Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
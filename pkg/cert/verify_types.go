@@ -0,0 +1,4 @@
+package cert
+
+// VerifyFailure categorizes why a certificate chain failed verification
+type VerifyFailure string
@@ -3,11 +3,14 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
 
 	"go.yaml.in/yaml/v3"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
 )
 
 // LoadConfig loads configuration from a YAML file
@@ -39,7 +42,7 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	for i, host := range config.Hosts {
-		if err := validateHost(host); err != nil {
+		if err := host.Validate(); err != nil {
 			return nil, fmt.Errorf("invalid host at index %d: %w", i, err)
 		}
 	}
@@ -47,6 +50,141 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// UnmarshalYAML accepts either a plain host string or a mapping
+func (e *HostEntry) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		e.Host = value.Value
+		return nil
+	}
+
+	type plain HostEntry
+	return value.Decode((*plain)(e))
+}
+
+// Validate validates a host entry and its per-host settings
+func (e HostEntry) Validate() error {
+	if strings.TrimSpace(e.Host) == "" && strings.TrimSpace(e.ConnectTo) == "" {
+		return fmt.Errorf("host or connect_to must be specified")
+	}
+
+	if e.Host != "" {
+		if err := validateHost(e.Host); err != nil {
+			return err
+		}
+	}
+
+	if e.ConnectTo != "" {
+		if err := validateHost(e.ConnectTo); err != nil {
+			return fmt.Errorf("invalid connect_to: %w", err)
+		}
+	}
+
+	if e.SNI != "" && e.NoSNI {
+		return fmt.Errorf("sni and no_sni are mutually exclusive")
+	}
+
+	if strings.ContainsAny(e.SNI, " :@/") {
+		return fmt.Errorf("invalid sni: %s", e.SNI)
+	}
+
+	if e.CA != "" {
+		if _, err := os.Stat(strings.TrimSpace(e.CA)); err != nil {
+			return fmt.Errorf("cannot access ca: %w", err)
+		}
+	}
+
+	if err := validateClientCert(e.clientCertSpec()); err != nil {
+		return err
+	}
+
+	for _, name := range e.ExpectedNames {
+		name = strings.TrimSpace(name)
+		if name == "" || (strings.ContainsAny(name, " :@/") && net.ParseIP(strings.Trim(name, "[]")) == nil) {
+			return fmt.Errorf("invalid expected name: %q", name)
+		}
+	}
+
+	for _, pin := range e.Pins {
+		if err := cert.ValidatePin(pin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Spec converts the entry into the checker's host specification
+func (e HostEntry) Spec() cert.HostSpec {
+	return cert.HostSpec{
+		Host:      strings.TrimSpace(e.Host),
+		ConnectTo: strings.TrimSpace(e.ConnectTo),
+		SNI:       strings.TrimSpace(e.SNI),
+		NoSNI:     e.NoSNI,
+		CA:        strings.TrimSpace(e.CA),
+
+		ClientCert: e.clientCertSpec(),
+
+		ExpectedNames: e.expectedNames(),
+		Pins:          e.pins(),
+	}
+}
+
+// pins returns the trimmed pins, nil when none are configured
+func (e HostEntry) pins() []string {
+	var pins []string
+	for _, pin := range e.Pins {
+		pins = append(pins, strings.TrimSpace(pin))
+	}
+
+	return pins
+}
+
+// expectedNames returns the trimmed expected names, nil when none are configured
+func (e HostEntry) expectedNames() []string {
+	var names []string
+	for _, name := range e.ExpectedNames {
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	return names
+}
+
+// clientCertSpec returns the entry's client certificate settings
+func (e HostEntry) clientCertSpec() cert.ClientCertSpec {
+	return cert.ClientCertSpec{
+		CertFile:       strings.TrimSpace(e.ClientCert),
+		KeyFile:        strings.TrimSpace(e.ClientKey),
+		PKCS12File:     strings.TrimSpace(e.ClientPKCS12),
+		PKCS12Password: e.ClientPKCS12Password,
+	}
+}
+
+// validateClientCert checks that a client certificate is fully specified and its files exist
+func validateClientCert(spec cert.ClientCertSpec) error {
+	if spec.IsZero() {
+		return nil
+	}
+
+	if spec.PKCS12File != "" {
+		if spec.CertFile != "" || spec.KeyFile != "" {
+			return fmt.Errorf("client PKCS#12 bundle and client certificate/key are mutually exclusive")
+		}
+	} else if spec.CertFile == "" || spec.KeyFile == "" {
+		return fmt.Errorf("client certificate and client key must be specified together")
+	}
+
+	for _, path := range []string{spec.CertFile, spec.KeyFile, spec.PKCS12File} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access client certificate file: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // ParseDomainsFromString parses a comma-separated string of domains
 func ParseDomainsFromString(domains string) ([]string, error) {
 	if domains == "" {
@@ -153,6 +291,28 @@ func validateHost(host string) error {
 		return fmt.Errorf("host cannot be empty")
 	}
 
+	scheme, rest := cert.SplitScheme(host)
+	if scheme != "" {
+		if err := validateScheme(scheme); err != nil {
+			return err
+		}
+		host = strings.TrimSpace(rest)
+		if host == "" {
+			return fmt.Errorf("host cannot be empty")
+		}
+	}
+
+	serverName, rest, hasServerName := cert.SplitServerName(host)
+	if hasServerName {
+		if strings.ContainsAny(serverName, " :/") {
+			return fmt.Errorf("invalid server name: %s", serverName)
+		}
+		host = strings.TrimSpace(rest)
+		if host == "" {
+			return fmt.Errorf("host cannot be empty")
+		}
+	}
+
 	// Handle IPv6 addresses with brackets [::1]:8080
 	if strings.HasPrefix(host, "[") {
 		closeBracket := strings.Index(host, "]")
@@ -212,10 +372,19 @@ func validateHost(host string) error {
 	return nil
 }
 
+// validateScheme checks that a protocol scheme is supported by the checker
+func validateScheme(scheme string) error {
+	if _, ok := cert.LookupNegotiator(scheme); !ok {
+		return fmt.Errorf("unsupported protocol: %s (supported: %s)", scheme, strings.Join(cert.SupportedSchemes(), ", "))
+	}
+
+	return nil
+}
+
 // Validate validates the application configuration
 func (c *AppConfig) Validate() error {
-	if c.ConfigFile == "" && c.Domains == "" && c.DomainsFile == "" {
-		return fmt.Errorf("one of --config, --domains, or --domains-file must be specified")
+	if !c.HasHostSource() && len(c.InspectPaths) == 0 && len(c.ManifestPaths) == 0 {
+		return fmt.Errorf("one of --config, --domains, --domains-file, --inspect, or --manifests must be specified")
 	}
 
 	sourceCount := 0
@@ -228,8 +397,41 @@ func (c *AppConfig) Validate() error {
 	if c.DomainsFile != "" {
 		sourceCount++
 	}
+	if len(c.InspectPaths) > 0 {
+		sourceCount++
+	}
 	if sourceCount > 1 {
-		return fmt.Errorf("--config, --domains, and --domains-file are mutually exclusive")
+		return fmt.Errorf("--config, --domains, --domains-file, and --inspect are mutually exclusive")
+	}
+
+	if len(c.InspectPaths) == 0 && len(c.InspectKeyFiles) > 0 {
+		return fmt.Errorf("--inspect-key can only be used with --inspect")
+	}
+
+	if len(c.InspectPaths) == 0 && (c.KeystorePass != "" || c.KeystorePassFile != "") {
+		return fmt.Errorf("--keystore-password and --keystore-password-file can only be used with --inspect")
+	}
+
+	if c.KeystorePass != "" && c.KeystorePassFile != "" {
+		return fmt.Errorf("--keystore-password and --keystore-password-file are mutually exclusive")
+	}
+
+	if c.KeystorePassFile != "" {
+		if _, err := os.Stat(c.KeystorePassFile); err != nil {
+			return fmt.Errorf("cannot access keystore password file: %w", err)
+		}
+	}
+
+	for _, path := range c.InspectKeyFiles {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access inspect key file: %w", err)
+		}
+	}
+
+	for _, path := range c.ManifestPaths {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access manifest path: %w", err)
+		}
 	}
 
 	if c.DomainsFileSkip < 0 {
@@ -248,6 +450,67 @@ func (c *AppConfig) Validate() error {
 		return fmt.Errorf("timeout must be positive")
 	}
 
+	if c.WarnDays < 0 {
+		return fmt.Errorf("warn days must be non-negative")
+	}
+
+	if c.CritDays < 0 {
+		return fmt.Errorf("crit days must be non-negative")
+	}
+
+	if c.WarnDays > 0 && c.CritDays > c.WarnDays {
+		return fmt.Errorf("--crit-days cannot be greater than --warn-days")
+	}
+
+	if c.Proxy != "" {
+		if _, err := cert.ParseProxyURL(c.Proxy); err != nil {
+			return fmt.Errorf("invalid --proxy value: %w", err)
+		}
+	}
+
+	if c.StartTLS != "" {
+		if err := validateScheme(c.StartTLS); err != nil {
+			return fmt.Errorf("invalid --starttls value: %w", err)
+		}
+	}
+
+	for _, path := range c.CAPaths() {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access CA path: %w", err)
+		}
+	}
+
+	// Without system roots, every certificate would otherwise fail as unknown_authority
+	if c.NoSystemRoots && len(c.CAPaths()) == 0 {
+		return fmt.Errorf("--no-system-roots requires --ca-file or --ca-dir")
+	}
+
+	if err := validateClientCert(c.ClientCertSpec()); err != nil {
+		return err
+	}
+
+	for _, path := range c.CRLFiles {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot access CRL file: %w", err)
+		}
+	}
+
+	if _, err := c.Rules(); err != nil {
+		return err
+	}
+
+	if c.CTLogList != "" {
+		if _, err := os.Stat(c.CTLogList); err != nil {
+			return fmt.Errorf("cannot access CT log list: %w", err)
+		}
+	}
+
+	if c.PolicyFile != "" {
+		if _, err := os.Stat(c.PolicyFile); err != nil {
+			return fmt.Errorf("cannot access policy file: %w", err)
+		}
+	}
+
 	if c.OutputFormat != "" && c.OutputFormat != "table" && c.OutputFormat != "json" && c.OutputFormat != "yaml" {
 		return fmt.Errorf("invalid output format: %s (supported: table, json, yaml)", c.OutputFormat)
 	}
@@ -259,14 +522,85 @@ func (c *AppConfig) Validate() error {
 	return nil
 }
 
+// CAPaths returns the CA files and directory to add to the trusted roots
+func (c *AppConfig) CAPaths() []string {
+	paths := append([]string(nil), c.CAFiles...)
+	if c.CADir != "" {
+		paths = append(paths, c.CADir)
+	}
+
+	return paths
+}
+
+// HasHostSource reports whether live hosts are to be checked
+func (c *AppConfig) HasHostSource() bool {
+	return c.ConfigFile != "" || c.Domains != "" || c.DomainsFile != ""
+}
+
+// InspectOptions returns the options for inspecting local certificate files,
+// reading the keystore password from its file when one is given
+func (c *AppConfig) InspectOptions() (cert.InspectOptions, error) {
+	password := c.KeystorePass
+	if c.KeystorePassFile != "" {
+		data, err := os.ReadFile(c.KeystorePassFile)
+		if err != nil {
+			return cert.InspectOptions{}, fmt.Errorf("cannot read keystore password file: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	return cert.InspectOptions{
+		KeyFiles: c.InspectKeyFiles,
+		Password: password,
+	}, nil
+}
+
+// Rules returns the default weak cryptography rules minus the disabled ones
+func (c *AppConfig) Rules() ([]cert.Rule, error) {
+	return cert.WithoutRules(cert.DefaultRules(), c.DisabledRules)
+}
+
+// ClientCertSpec returns the client certificate presented to every host without its own
+func (c *AppConfig) ClientCertSpec() cert.ClientCertSpec {
+	return cert.ClientCertSpec{
+		CertFile:       c.ClientCert,
+		KeyFile:        c.ClientKey,
+		PKCS12File:     c.ClientPKCS12,
+		PKCS12Password: c.ClientPKCS12Pass,
+	}
+}
+
 // GetHosts returns the list of hosts based on the configuration
 func (c *AppConfig) GetHosts() ([]string, error) {
+	specs, err := c.GetHostSpecs()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, len(specs))
+	for i, spec := range specs {
+		hosts[i] = spec.Host
+		if hosts[i] == "" {
+			hosts[i] = spec.ConnectTo
+		}
+	}
+
+	return hosts, nil
+}
+
+// GetHostSpecs returns the host entries, including per-host settings from the config file
+func (c *AppConfig) GetHostSpecs() ([]cert.HostSpec, error) {
 	if c.ConfigFile != "" {
 		config, err := LoadConfig(c.ConfigFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load config file: %w", err)
 		}
-		return config.Hosts, nil
+
+		specs := make([]cert.HostSpec, len(config.Hosts))
+		for i, entry := range config.Hosts {
+			specs[i] = entry.Spec()
+		}
+		return specs, nil
 	}
 
 	if c.Domains != "" {
@@ -274,7 +608,7 @@ func (c *AppConfig) GetHosts() ([]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse domains: %w", err)
 		}
-		return hosts, nil
+		return hostSpecs(hosts), nil
 	}
 
 	if c.DomainsFile != "" {
@@ -282,8 +616,18 @@ func (c *AppConfig) GetHosts() ([]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse domains file: %w", err)
 		}
-		return hosts, nil
+		return hostSpecs(hosts), nil
 	}
 
 	return nil, fmt.Errorf("no hosts configuration provided")
 }
+
+// hostSpecs wraps plain host strings into host specifications
+func hostSpecs(hosts []string) []cert.HostSpec {
+	specs := make([]cert.HostSpec, len(hosts))
+	for i, host := range hosts {
+		specs[i] = cert.HostSpec{Host: host}
+	}
+
+	return specs
+}
@@ -1,7 +1,27 @@
 package config
 
 type Config struct {
-	Hosts []string `yaml:"hosts"`
+	Hosts []HostEntry `yaml:"hosts"`
+}
+
+// HostEntry is a host in the YAML config, written either as a plain
+// host string or as a mapping with per-host settings
+type HostEntry struct {
+	Host      string `yaml:"host"`
+	ConnectTo string `yaml:"connect_to"`
+	SNI       string `yaml:"sni"`
+	NoSNI     bool   `yaml:"no_sni"`
+	CA        string `yaml:"ca"`
+
+	ClientCert           string `yaml:"client_cert"`
+	ClientKey            string `yaml:"client_key"`
+	ClientPKCS12         string `yaml:"client_p12"`
+	ClientPKCS12Password string `yaml:"client_p12_password"`
+
+	// ExpectedNames must all be covered by the certificate's SANs
+	ExpectedNames []string `yaml:"expected_names"`
+	// Pins are certificate or SPKI SHA-256 hashes, one of which the presented chain must match
+	Pins []string `yaml:"pins"`
 }
 
 type AppConfig struct {
@@ -10,8 +30,36 @@ type AppConfig struct {
 	DomainsFile      string
 	DomainsFileSkip  int
 	DomainsFileLimit int
+	InspectPaths     []string
+	InspectKeyFiles  []string
+	KeystorePass     string
+	KeystorePassFile string
+	ManifestPaths    []string
 	Timeout          int
+	Proxy            string
+	StartTLS         string
+	AllIPs           bool
+	ScanProtocols    bool
+	OCSP             bool
+	CAFiles          []string
+	CADir            string
+	NoSystemRoots    bool
+	ClientCert       string
+	ClientKey        string
+	ClientPKCS12     string
+	ClientPKCS12Pass string
+	CRL              bool
+	CRLFiles         []string
+	CRLCacheDir      string
+	WarnDays         int
+	CritDays         int
 	Insecure         bool
+	DisabledRules    []string
+	CT               bool
+	CTLogList        string
+	PolicyFile       string
+	ShowChain        bool
 	OutputFormat     string
+	Fields           []string
 	OutputFile       string
 }
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const (
+	// SecretTypeTLS is the type of Secrets holding a certificate in tls.crt and its key in tls.key
+	SecretTypeTLS = "kubernetes.io/tls"
+
+	defaultNamespace = "default"
+	certManagerGroup = "cert-manager.io/"
+)
+
+// LoadManifests reads the kubernetes.io/tls Secrets of Kubernetes manifests,
+// labeled namespace/name. Directories are walked recursively for .yaml and .yml
+// files; files in them that are not valid YAML, such as Helm templates, are skipped.
+// A cert-manager Certificate for a Secret supplies the names it must cover.
+func LoadManifests(paths []string) ([]cert.CertificateSource, []cert.ErrorInfo) {
+	var objects manifestObjects
+	var errs []cert.ErrorInfo
+
+	for _, root := range paths {
+		files, walked, err := manifestFiles(root)
+		if err != nil {
+			errs = append(errs, cert.ErrorInfo{Host: root, Error: err.Error()})
+			continue
+		}
+
+		for _, file := range files {
+			err := objects.load(file)
+			if err != nil && !(walked && errors.Is(err, errInvalidYAML)) {
+				errs = append(errs, cert.ErrorInfo{Host: file, Error: err.Error()})
+			}
+		}
+	}
+
+	sources, sourceErrs := objects.sources()
+
+	return sources, append(errs, sourceErrs...)
+}
+
+// errInvalidYAML marks manifest files that cannot be parsed at all
+var errInvalidYAML = errors.New("invalid YAML format")
+
+// manifestFiles returns path, or the YAML files below it when it is a directory
+func manifestFiles(root string) (files []string, walked bool, err error) {
+	stat, err := os.Stat(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot access manifest path: %w", err)
+	}
+	if !stat.IsDir() {
+		return []string{root}, false, nil
+	}
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, true, fmt.Errorf("cannot walk manifest directory: %w", err)
+	}
+
+	return files, true, nil
+}
+
+// load adds the objects of every document in a manifest file
+func (o *manifestObjects) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read manifest file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidYAML, err)
+		}
+
+		if err := o.add(&node); err != nil {
+			return err
+		}
+	}
+}
+
+// add records node when it is a TLS Secret or a cert-manager Certificate,
+// descending into Lists. Objects of any other kind are ignored.
+func (o *manifestObjects) add(node *yaml.Node) error {
+	var header manifestHeader
+	if err := node.Decode(&header); err != nil {
+		// Not an object, such as a document holding only comments
+		return nil
+	}
+
+	switch {
+	case header.Kind == "Secret" && header.APIVersion == "v1":
+		var secret tlsSecret
+		if err := node.Decode(&secret); err != nil {
+			return fmt.Errorf("invalid Secret: %w", err)
+		}
+		if secret.Type == SecretTypeTLS {
+			o.secrets = append(o.secrets, secret)
+		}
+	case header.Kind == "Certificate" && strings.HasPrefix(header.APIVersion, certManagerGroup):
+		var certificate certificateResource
+		if err := node.Decode(&certificate); err != nil {
+			return fmt.Errorf("invalid Certificate: %w", err)
+		}
+		o.certificates = append(o.certificates, certificate)
+	case strings.HasSuffix(header.Kind, "List"):
+		var list manifestList
+		if err := node.Decode(&list); err != nil {
+			return fmt.Errorf("invalid %s: %w", header.Kind, err)
+		}
+		for i := range list.Items {
+			if err := o.add(&list.Items[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sources returns a certificate source per TLS Secret with a certificate.
+// Secrets whose tls.crt is empty, such as placeholders for cert-manager, are skipped.
+func (o *manifestObjects) sources() ([]cert.CertificateSource, []cert.ErrorInfo) {
+	expectedNames := make(map[string][]string)
+	for _, certificate := range o.certificates {
+		key := certificate.Metadata.label(certificate.Spec.SecretName)
+		names := append(slices.Clone(certificate.Spec.DNSNames), certificate.Spec.IPAddresses...)
+		if cn := certificate.Spec.CommonName; cn != "" && !slices.Contains(names, cn) {
+			names = append(names, cn)
+		}
+		expectedNames[key] = names
+	}
+
+	var sources []cert.CertificateSource
+	var errs []cert.ErrorInfo
+	for _, secret := range o.secrets {
+		label := secret.Metadata.label(secret.Metadata.Name)
+
+		crt, err := secret.value("tls.crt")
+		if err != nil {
+			errs = append(errs, cert.ErrorInfo{Host: label, Error: err.Error()})
+			continue
+		}
+		if len(crt) == 0 {
+			continue
+		}
+
+		// Keys are often encrypted in GitOps repositories, so an unreadable key is not an error
+		key, _ := secret.value("tls.key")
+
+		sources = append(sources, cert.CertificateSource{
+			Label:         label,
+			Certificates:  crt,
+			Key:           key,
+			ExpectedNames: expectedNames[label],
+		})
+	}
+
+	return sources, errs
+}
+
+// value returns a Secret entry, decoding it from data or taking it as is from stringData
+func (s tlsSecret) value(name string) ([]byte, error) {
+	if value, ok := s.StringData[name]; ok {
+		return []byte(value), nil
+	}
+
+	value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s.Data[name]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 in %s: %w", name, err)
+	}
+
+	return value, nil
+}
+
+// label returns namespace/name for an object in the namespace of m
+func (m manifestMetadata) label(name string) string {
+	namespace := m.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return namespace + "/" + name
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifests(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"app/site.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: site-tls
+  namespace: web
+type: kubernetes.io/tls
+data:
+  tls.crt: ` + b64("site certificate") + `
+  tls.key: ` + b64("site key") + `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  nested: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+type: Opaque
+data:
+  tls.crt: ` + b64("not a TLS secret") + `
+---
+# comments only
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: site
+  namespace: web
+spec:
+  secretName: site-tls
+  commonName: www.example.com
+  dnsNames:
+    - example.com
+  ipAddresses:
+    - 192.0.2.10
+`,
+		"list.yml": `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: list-tls
+    type: kubernetes.io/tls
+    stringData:
+      tls.crt: list certificate
+      tls.key: ENC[AES256_GCM,data:abc,type:str]
+`,
+		"placeholder.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: pending-tls
+  namespace: web
+type: kubernetes.io/tls
+data:
+  tls.crt: ""
+  tls.key: ""
+`,
+		"broken.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: broken-tls
+  namespace: web
+type: kubernetes.io/tls
+data:
+  tls.crt: "not base64!"
+`,
+		"chart/templates/secret.yaml": "{{- if .Values.tls }}\nkind: [\n",
+		".git/secret.yaml":            "apiVersion: v1\nkind: Secret\nmetadata: {name: hidden}\ntype: kubernetes.io/tls\n",
+		"README.md":                   "kind: Secret\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	sources, errs := LoadManifests([]string{dir})
+
+	if len(sources) != 2 {
+		t.Fatalf("LoadManifests() returned %d sources, want 2: %+v", len(sources), sources)
+	}
+	byLabel := make(map[string]int)
+	for i, source := range sources {
+		byLabel[source.Label] = i
+	}
+
+	site, ok := byLabel["web/site-tls"]
+	if !ok {
+		t.Fatalf("LoadManifests() missing web/site-tls")
+	}
+	if string(sources[site].Certificates) != "site certificate" || string(sources[site].Key) != "site key" {
+		t.Errorf("web/site-tls decoded to %q and %q", sources[site].Certificates, sources[site].Key)
+	}
+	wantNames := []string{"example.com", "192.0.2.10", "www.example.com"}
+	if !reflect.DeepEqual(sources[site].ExpectedNames, wantNames) {
+		t.Errorf("web/site-tls ExpectedNames = %v, want %v", sources[site].ExpectedNames, wantNames)
+	}
+
+	list, ok := byLabel["default/list-tls"]
+	if !ok {
+		t.Fatalf("LoadManifests() missing default/list-tls from the List")
+	}
+	if string(sources[list].Certificates) != "list certificate" || sources[list].ExpectedNames != nil {
+		t.Errorf("default/list-tls = %+v, want stringData certificate without expected names", sources[list])
+	}
+
+	if len(errs) != 1 || errs[0].Host != "web/broken-tls" || !strings.Contains(errs[0].Error, "invalid base64") {
+		t.Errorf("LoadManifests() errors = %v, want invalid base64 for web/broken-tls", errs)
+	}
+}
+
+func TestLoadManifests_ExplicitFiles(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("kind: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.yaml")
+
+	sources, errs := LoadManifests([]string{invalid, missing})
+	if len(sources) != 0 {
+		t.Errorf("LoadManifests() returned %d sources, want 0", len(sources))
+	}
+
+	want := map[string]string{
+		invalid: "invalid YAML format",
+		missing: "cannot access manifest path",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("LoadManifests() errors = %v, want %d", errs, len(want))
+	}
+	for _, e := range errs {
+		if !strings.Contains(e.Error, want[e.Host]) {
+			t.Errorf("LoadManifests() error for %s = %q, want %q", e.Host, e.Error, want[e.Host])
+		}
+	}
+}
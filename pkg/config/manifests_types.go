@@ -0,0 +1,45 @@
+package config
+
+import "go.yaml.in/yaml/v3"
+
+// manifestHeader identifies a Kubernetes object before it is decoded in full,
+// so that objects of other kinds never have to match the fields read here
+type manifestHeader struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+}
+
+// manifestList is a List of objects, as written by kubectl get -o yaml
+type manifestList struct {
+	Items []yaml.Node `yaml:"items"`
+}
+
+// tlsSecret is a Secret, holding the certificate when its type is kubernetes.io/tls
+type tlsSecret struct {
+	Metadata   manifestMetadata  `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+	StringData map[string]string `yaml:"stringData"`
+}
+
+// certificateResource is a cert-manager Certificate, naming its Secret and the SANs it requests
+type certificateResource struct {
+	Metadata manifestMetadata `yaml:"metadata"`
+	Spec     struct {
+		SecretName  string   `yaml:"secretName"`
+		CommonName  string   `yaml:"commonName"`
+		DNSNames    []string `yaml:"dnsNames"`
+		IPAddresses []string `yaml:"ipAddresses"`
+	} `yaml:"spec"`
+}
+
+type manifestMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+// manifestObjects collects the TLS Secrets and Certificates found in manifests
+type manifestObjects struct {
+	secrets      []tlsSecret
+	certificates []certificateResource
+}
@@ -0,0 +1,389 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// LoadPolicy loads and validates a policy from a YAML file
+func LoadPolicy(policyPath string) (*Policy, error) {
+	if policyPath == "" {
+		return nil, fmt.Errorf("policy file path cannot be empty")
+	}
+
+	data, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid YAML format: %w", err)
+	}
+
+	if len(policy.Rules) == 0 {
+		return nil, fmt.Errorf("no rules found in policy file")
+	}
+
+	seen := make(map[string]bool, len(policy.Rules))
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rule at index %d: %w", i, err)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("invalid rule at index %d: duplicate id %s", i, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+
+	return &policy, nil
+}
+
+// CertRules converts the policy into rules for the findings engine
+func (p *Policy) CertRules() []cert.Rule {
+	rules := make([]cert.Rule, len(p.Rules))
+	for i, rule := range p.Rules {
+		rules[i] = cert.Rule{
+			ID:       rule.ID,
+			Severity: rule.severity(),
+			Check:    rule.check,
+		}
+	}
+
+	return rules
+}
+
+// validate checks the rule and compiles its patterns
+func (r *PolicyRule) validate() error {
+	r.ID = strings.TrimSpace(r.ID)
+	if r.ID == "" {
+		return fmt.Errorf("id must be specified")
+	}
+
+	switch cert.Severity(r.Severity) {
+	case "", cert.SeverityInfo, cert.SeverityWarning, cert.SeverityCritical:
+	default:
+		return fmt.Errorf("invalid severity: %s (supported: info, warning, critical)", r.Severity)
+	}
+
+	for _, pattern := range r.Hosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+		}
+	}
+
+	if len(r.Require) == 0 {
+		return fmt.Errorf("require must list at least one field")
+	}
+
+	for field, condition := range r.Require {
+		kind, err := certificateFieldKind(field)
+		if err != nil {
+			return err
+		}
+		if err := condition.validate(kind); err != nil {
+			return fmt.Errorf("field %s: %w", field, err)
+		}
+		if condition.Matches != "" {
+			condition.matcher, err = regexp.Compile(condition.Matches)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid matches pattern: %w", field, err)
+			}
+		}
+		r.Require[field] = condition
+	}
+
+	return nil
+}
+
+// severity returns the rule's severity, warning when none is set
+func (r PolicyRule) severity() cert.Severity {
+	if r.Severity == "" {
+		return cert.SeverityWarning
+	}
+
+	return cert.Severity(r.Severity)
+}
+
+// check evaluates the rule against a certificate, skipping hosts the rule does not cover
+func (r PolicyRule) check(info *cert.CertificateInfo) (string, bool) {
+	if !r.matchesHost(info.Host) {
+		return "", false
+	}
+
+	fields, err := certificateFields(info)
+	if err != nil {
+		return fmt.Sprintf("cannot evaluate policy: %v", err), true
+	}
+
+	names := make([]string, 0, len(r.Require))
+	for name := range r.Require {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		reason, ok := r.Require[name].evaluate(lookupField(fields, name))
+		if ok {
+			continue
+		}
+
+		if r.Message != "" {
+			return r.Message, true
+		}
+		return fmt.Sprintf("%s %s", name, reason), true
+	}
+
+	return "", false
+}
+
+// matchesHost reports whether the rule applies to the host label of a result
+func (r PolicyRule) matchesHost(label string) bool {
+	if len(r.Hosts) == 0 {
+		return true
+	}
+
+	host := strings.ToLower(labelHostname(label))
+	for _, pattern := range r.Hosts {
+		if matched, _ := path.Match(strings.ToLower(pattern), host); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// labelHostname extracts the server name from a result label such as
+// "smtp://name@10.0.0.5:25", falling back to the dialed address
+func labelHostname(label string) string {
+	if _, rest, ok := strings.Cut(label, "://"); ok {
+		label = rest
+	}
+
+	if name, address, ok := strings.Cut(label, "@"); ok {
+		if name != "" {
+			return name
+		}
+		label = address
+	}
+
+	if host, _, err := net.SplitHostPort(label); err == nil {
+		return host
+	}
+
+	return label
+}
+
+// validate checks that the operators suit a field of the given kind
+func (c Condition) validate(kind reflect.Kind) error {
+	if c.Equals == nil && c.NotEquals == nil && len(c.OneOf) == 0 && len(c.NotOneOf) == 0 &&
+		c.Matches == "" && len(c.Contains) == 0 && c.Min == nil && c.Max == nil {
+		return fmt.Errorf("condition has no operator")
+	}
+
+	if (c.Min != nil || c.Max != nil) && !isNumericKind(kind) {
+		return fmt.Errorf("min and max require a numeric field")
+	}
+
+	return nil
+}
+
+// evaluate checks value, as decoded from JSON, against the condition and
+// explains the first violation
+func (c Condition) evaluate(value any) (string, bool) {
+	if list, ok := value.([]any); ok {
+		values := make([]string, len(list))
+		for i, element := range list {
+			values[i] = scalarString(element)
+		}
+		for _, want := range c.Contains {
+			if !slices.Contains(values, want) {
+				return fmt.Sprintf("does not contain %q", want), false
+			}
+		}
+		for _, element := range list {
+			if reason, ok := c.evaluateScalar(element); !ok {
+				return reason, false
+			}
+		}
+		return "", true
+	}
+
+	if len(c.Contains) > 0 {
+		got := scalarString(value)
+		for _, want := range c.Contains {
+			if !strings.Contains(got, want) {
+				return fmt.Sprintf("is %q, does not contain %q", got, want), false
+			}
+		}
+	}
+
+	return c.evaluateScalar(value)
+}
+
+// evaluateScalar applies every operator except contains to a single value
+func (c Condition) evaluateScalar(value any) (string, bool) {
+	got := scalarString(value)
+
+	if c.Equals != nil && got != *c.Equals {
+		return fmt.Sprintf("is %q, expected %q", got, *c.Equals), false
+	}
+	if c.NotEquals != nil && got == *c.NotEquals {
+		return fmt.Sprintf("must not be %q", got), false
+	}
+	if len(c.OneOf) > 0 && !slices.Contains(c.OneOf, got) {
+		return fmt.Sprintf("is %q, expected one of %s", got, strings.Join(c.OneOf, ", ")), false
+	}
+	if slices.Contains(c.NotOneOf, got) {
+		return fmt.Sprintf("must not be %q", got), false
+	}
+	if c.matcher != nil && !c.matcher.MatchString(got) {
+		return fmt.Sprintf("is %q, does not match %s", got, c.Matches), false
+	}
+
+	// A field omitted from the output, such as the connection of an inspected
+	// file, has no value to compare
+	if (c.Min != nil || c.Max != nil) && value != nil {
+		number, _ := value.(float64)
+		if c.Min != nil && number < *c.Min {
+			return fmt.Sprintf("is %s, below the minimum of %s", got, formatNumber(*c.Min)), false
+		}
+		if c.Max != nil && number > *c.Max {
+			return fmt.Sprintf("is %s, above the maximum of %s", got, formatNumber(*c.Max)), false
+		}
+	}
+
+	return "", true
+}
+
+// certificateFields decodes the certificate as it appears in the JSON output
+func certificateFields(info *cert.CertificateInfo) (map[string]any, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// lookupField resolves a dotted field name; omitted fields resolve to nil
+func lookupField(fields map[string]any, name string) any {
+	var value any = fields
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+
+	return value
+}
+
+// certificateFieldKind returns the kind of a dotted CertificateInfo field, by
+// JSON name, and rejects fields that are not scalars or lists of scalars
+func certificateFieldKind(name string) (reflect.Kind, error) {
+	typ := reflect.TypeOf(cert.CertificateInfo{})
+	for _, part := range strings.Split(name, ".") {
+		field, ok := fieldByJSONName(typ, part)
+		if !ok {
+			return reflect.Invalid, fmt.Errorf("unknown field: %s", name)
+		}
+
+		typ = field.Type
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+	}
+
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		return reflect.String, nil
+	}
+	if typ.Kind() == reflect.Struct || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		return reflect.Invalid, fmt.Errorf("field %s is not a value; use one of its nested fields", name)
+	}
+
+	return typ.Kind(), nil
+}
+
+// fieldByJSONName finds the struct field serialized under name
+func fieldByJSONName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	if typ.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if tag == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// isNumericKind reports whether kind holds integers or floats
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// scalarString renders a JSON-decoded scalar for comparison; nil renders as ""
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return formatNumber(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatNumber renders a number without a trailing fraction when it is whole
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
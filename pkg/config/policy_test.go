@@ -0,0 +1,262 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const testPolicy = `rules:
+  - id: corp-issuer
+    hosts: ["*.corp.example.com"]
+    require:
+      issuer:
+        one_of: ["Corp CA 1", "Corp CA 2"]
+    severity: critical
+    message: corp hosts must use the internal CA
+  - id: ecdsa-p256
+    require:
+      public_key_algorithm:
+        equals: ECDSA
+      key_curve:
+        equals: P-256
+  - id: rsa-size
+    hosts: ["legacy.example.com"]
+    require:
+      key_size:
+        equals: 4096
+  - id: dns-names
+    hosts: ["www.example.com"]
+    require:
+      dns_names:
+        matches: '(^|\.)example\.com$'
+        contains: [example.com]
+  - id: renewal-margin
+    require:
+      days_remaining:
+        min: 14
+    severity: info
+  - id: modern-tls
+    require:
+      connection.tls_version:
+        not_one_of: [TLS 1.0, TLS 1.1]
+      connection.handshake_ms:
+        max: 1000
+  - id: key-strength
+    require:
+      key_size:
+        min: 256
+`
+
+func writeTestPolicy(t *testing.T, content string) string {
+	t.Helper()
+
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+
+	return policyPath
+}
+
+func TestLoadPolicy(t *testing.T) {
+	policy, err := LoadPolicy(writeTestPolicy(t, testPolicy))
+	if err != nil {
+		t.Fatalf("LoadPolicy() unexpected error: %v", err)
+	}
+
+	if len(policy.Rules) != 7 {
+		t.Fatalf("LoadPolicy() rules = %d, want 7", len(policy.Rules))
+	}
+
+	rules := policy.CertRules()
+	if rules[0].Severity != cert.SeverityCritical || rules[1].Severity != cert.SeverityWarning {
+		t.Errorf("CertRules() severities = %s, %s, want critical, warning", rules[0].Severity, rules[1].Severity)
+	}
+
+	invalid := map[string]string{
+		"empty":             "rules: []\n",
+		"missing id":        "rules:\n  - require: {issuer: {equals: X}}\n",
+		"duplicate id":      "rules:\n  - id: a\n    require: {issuer: {equals: X}}\n  - id: a\n    require: {issuer: {equals: Y}}\n",
+		"no require":        "rules:\n  - id: a\n",
+		"unknown field":     "rules:\n  - id: a\n    require: {issuer_name: {equals: X}}\n",
+		"unknown nested":    "rules:\n  - id: a\n    require: {connection.version: {equals: X}}\n",
+		"object field":      "rules:\n  - id: a\n    require: {connection: {equals: X}}\n",
+		"no operator":       "rules:\n  - id: a\n    require: {issuer: {}}\n",
+		"min on string":     "rules:\n  - id: a\n    require: {issuer: {min: 1}}\n",
+		"bad regexp":        "rules:\n  - id: a\n    require: {issuer: {matches: '('}}\n",
+		"bad severity":      "rules:\n  - id: a\n    severity: fatal\n    require: {issuer: {equals: X}}\n",
+		"bad host pattern":  "rules:\n  - id: a\n    hosts: ['[']\n    require: {issuer: {equals: X}}\n",
+		"invalid yaml":      "rules: [\n",
+		"unknown top level": "rules: {id: a}\n",
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadPolicy(writeTestPolicy(t, content)); err == nil {
+				t.Error("LoadPolicy() expected error but got none")
+			}
+		})
+	}
+
+	if _, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadPolicy() should return error for a missing file")
+	}
+}
+
+func TestPolicy_CertRules(t *testing.T) {
+	policy, err := LoadPolicy(writeTestPolicy(t, testPolicy))
+	if err != nil {
+		t.Fatalf("LoadPolicy() unexpected error: %v", err)
+	}
+
+	compliant := cert.CertificateInfo{
+		Host:               "www.example.com:443",
+		DNSNames:           []string{"example.com", "www.example.com"},
+		Issuer:             "Public CA",
+		PublicKeyAlgorithm: "ECDSA",
+		KeyCurve:           "P-256",
+		KeySize:            256,
+		DaysRemaining:      60,
+		Status:             cert.StatusOK,
+		Connection:         &cert.ConnectionInfo{TLSVersion: "TLS 1.3"},
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(info *cert.CertificateInfo)
+		wantIDs     []string
+		wantMessage string
+		wantStatus  cert.Status
+	}{
+		{
+			name:       "compliant",
+			modify:     func(info *cert.CertificateInfo) {},
+			wantStatus: cert.StatusOK,
+		},
+		{
+			name: "corp host with public issuer",
+			modify: func(info *cert.CertificateInfo) {
+				info.Host = "smtp://mail.corp.example.com@10.0.0.5:25"
+			},
+			wantIDs:     []string{"corp-issuer"},
+			wantMessage: "corp hosts must use the internal CA",
+			wantStatus:  cert.StatusCritical,
+		},
+		{
+			name: "corp host with corp issuer",
+			modify: func(info *cert.CertificateInfo) {
+				info.Host = "app.corp.example.com:443"
+				info.Issuer = "Corp CA 2"
+			},
+			wantStatus: cert.StatusOK,
+		},
+		{
+			name: "RSA key",
+			modify: func(info *cert.CertificateInfo) {
+				info.Host = "legacy.example.com:443"
+				info.PublicKeyAlgorithm, info.KeyCurve, info.KeySize = "RSA", "", 2048
+			},
+			wantIDs:     []string{"ecdsa-p256", "rsa-size"},
+			wantMessage: `key_curve is "", expected "P-256"`,
+			wantStatus:  cert.StatusWarning,
+		},
+		{
+			name: "foreign DNS name",
+			modify: func(info *cert.CertificateInfo) {
+				info.DNSNames = []string{"example.com", "www.example.org"}
+			},
+			wantIDs:     []string{"dns-names"},
+			wantMessage: `dns_names is "www.example.org", does not match (^|\.)example\.com$`,
+			wantStatus:  cert.StatusWarning,
+		},
+		{
+			name: "missing DNS name",
+			modify: func(info *cert.CertificateInfo) {
+				info.DNSNames = []string{"www.example.com"}
+			},
+			wantIDs:     []string{"dns-names"},
+			wantMessage: `dns_names does not contain "example.com"`,
+			wantStatus:  cert.StatusWarning,
+		},
+		{
+			name:        "renewal margin",
+			modify:      func(info *cert.CertificateInfo) { info.DaysRemaining = 10 },
+			wantIDs:     []string{"renewal-margin"},
+			wantMessage: "days_remaining is 10, below the minimum of 14",
+			wantStatus:  cert.StatusOK,
+		},
+		{
+			name:        "legacy TLS",
+			modify:      func(info *cert.CertificateInfo) { info.Connection = &cert.ConnectionInfo{TLSVersion: "TLS 1.0"} },
+			wantIDs:     []string{"modern-tls"},
+			wantMessage: `connection.tls_version must not be "TLS 1.0"`,
+			wantStatus:  cert.StatusWarning,
+		},
+		{
+			name: "slow handshake",
+			modify: func(info *cert.CertificateInfo) {
+				info.Connection = &cert.ConnectionInfo{TLSVersion: "TLS 1.3", HandshakeMillis: 1500}
+			},
+			wantIDs:     []string{"modern-tls"},
+			wantMessage: "connection.handshake_ms is 1500, above the maximum of 1000",
+			wantStatus:  cert.StatusWarning,
+		},
+		{
+			name:       "no connection details",
+			modify:     func(info *cert.CertificateInfo) { info.Connection = nil },
+			wantStatus: cert.StatusOK,
+		},
+		{
+			name:       "unknown key size",
+			modify:     func(info *cert.CertificateInfo) { info.KeySize = 0 },
+			wantStatus: cert.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := compliant
+			tt.modify(&info)
+			cert.ApplyRules(&info, policy.CertRules())
+
+			if len(info.Findings) != len(tt.wantIDs) {
+				t.Fatalf("ApplyRules() findings = %+v, want IDs %v", info.Findings, tt.wantIDs)
+			}
+			for i, finding := range info.Findings {
+				if finding.ID != tt.wantIDs[i] {
+					t.Errorf("Findings[%d].ID = %q, want %q", i, finding.ID, tt.wantIDs[i])
+				}
+			}
+			if tt.wantMessage != "" && info.Findings[0].Message != tt.wantMessage {
+				t.Errorf("Findings[0].Message = %q, want %q", info.Findings[0].Message, tt.wantMessage)
+			}
+
+			if info.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", info.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLabelHostname(t *testing.T) {
+	tests := map[string]string{
+		"example.com:443":                 "example.com",
+		"www.example.com@10.0.0.5:443":    "www.example.com",
+		"@10.0.0.5:443":                   "10.0.0.5",
+		"smtp://mail.example.com:25":      "mail.example.com",
+		"smtp://mx.example.com@[::1]:25":  "mx.example.com",
+		"[2001:db8::1]:8443":              "2001:db8::1",
+		"postgres://@db.example.com:5432": "db.example.com",
+		"example.com":                     "example.com",
+	}
+
+	for label, want := range tests {
+		t.Run(label, func(t *testing.T) {
+			if got := labelHostname(label); got != want {
+				t.Errorf("labelHostname(%q) = %q, want %q", label, got, want)
+			}
+		})
+	}
+}
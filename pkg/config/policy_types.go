@@ -0,0 +1,35 @@
+package config
+
+import "regexp"
+
+// Policy is a set of user-defined certificate rules loaded from YAML
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule requires certificates of matching hosts to satisfy every condition
+type PolicyRule struct {
+	ID string `yaml:"id"`
+	// Hosts are glob patterns matched against the checked host name; empty means every host
+	Hosts []string `yaml:"hosts"`
+	// Require maps a certificate field, as named in the JSON output, to its condition.
+	// Nested fields use dots, e.g. connection.tls_version.
+	Require  map[string]Condition `yaml:"require"`
+	Severity string               `yaml:"severity"`
+	Message  string               `yaml:"message"`
+}
+
+// Condition constrains a certificate field. Every operator that is set must hold;
+// for list fields the operators apply to each element, except contains.
+type Condition struct {
+	Equals    *string  `yaml:"equals"`
+	NotEquals *string  `yaml:"not_equals"`
+	OneOf     []string `yaml:"one_of"`
+	NotOneOf  []string `yaml:"not_one_of"`
+	Matches   string   `yaml:"matches"`
+	Contains  []string `yaml:"contains"`
+	Min       *float64 `yaml:"min"`
+	Max       *float64 `yaml:"max"`
+
+	matcher *regexp.Regexp
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// defaultFields are the table columns shown without --fields
+var defaultFields = []string{
+	"host",
+	"common_name",
+	"dns_names",
+	"not_before",
+	"not_after",
+	"public_key_algorithm",
+	"issuer",
+	"days_remaining",
+	"status",
+	"verified",
+}
+
+var columns = []column{
+	{name: "host", header: "Host", value: func(c cert.CertificateInfo) any { return c.Host }},
+	{name: "common_name", header: "Common Name", value: func(c cert.CertificateInfo) any { return c.CommonName }},
+	{name: "dns_names", header: "DNS Names", value: func(c cert.CertificateInfo) any { return joinLines(c.DNSNames) }},
+	{name: "not_before", header: "Not Before", value: func(c cert.CertificateInfo) any { return c.NotBefore }},
+	{name: "not_after", header: "Not After", value: func(c cert.CertificateInfo) any { return c.NotAfter }},
+	{name: "public_key_algorithm", header: "PublicKeyAlgorithm", value: func(c cert.CertificateInfo) any { return c.PublicKeyAlgorithm }},
+	{name: "issuer", header: "Issuer", value: func(c cert.CertificateInfo) any { return c.Issuer }},
+	{name: "days_remaining", header: "Days Remaining", value: func(c cert.CertificateInfo) any { return c.DaysRemaining }},
+	{name: "status", header: "Status", value: func(c cert.CertificateInfo) any { return c.Status }},
+	{name: "verified", header: "Verified", value: func(c cert.CertificateInfo) any { return formatVerified(c) }},
+
+	{name: "subject", header: "Subject", value: func(c cert.CertificateInfo) any { return c.Subject }},
+	{name: "issuer_dn", header: "Issuer DN", value: func(c cert.CertificateInfo) any { return c.IssuerDN }},
+	{name: "serial_number", header: "Serial Number", value: func(c cert.CertificateInfo) any { return c.SerialNumber }},
+	{name: "sha1_fingerprint", header: "SHA-1 Fingerprint", value: func(c cert.CertificateInfo) any { return c.SHA1Fingerprint }},
+	{name: "sha256_fingerprint", header: "SHA-256 Fingerprint", value: func(c cert.CertificateInfo) any { return c.SHA256Fingerprint }},
+	{name: "spki_sha256", header: "SPKI SHA-256", value: func(c cert.CertificateInfo) any { return c.SPKISHA256 }},
+	{name: "signature_algorithm", header: "Signature Algorithm", value: func(c cert.CertificateInfo) any { return c.SignatureAlgorithm }},
+	{name: "key_size", header: "Key Size", value: func(c cert.CertificateInfo) any { return c.KeySize }},
+	{name: "key_curve", header: "Key Curve", value: func(c cert.CertificateInfo) any { return c.KeyCurve }},
+	{name: "key_usage", header: "Key Usage", value: func(c cert.CertificateInfo) any { return joinLines(c.KeyUsage) }},
+	{name: "ext_key_usage", header: "Ext Key Usage", value: func(c cert.CertificateInfo) any { return joinLines(c.ExtKeyUsage) }},
+	{name: "ip_addresses", header: "IP Addresses", value: func(c cert.CertificateInfo) any { return joinLines(c.IPAddresses) }},
+	{name: "email_addresses", header: "Email Addresses", value: func(c cert.CertificateInfo) any { return joinLines(c.EmailAddresses) }},
+	{name: "uris", header: "URIs", value: func(c cert.CertificateInfo) any { return joinLines(c.URIs) }},
+	{name: "policy_oids", header: "Policy OIDs", value: func(c cert.CertificateInfo) any { return joinLines(c.PolicyOIDs) }},
+	{name: "ocsp_servers", header: "OCSP Servers", value: func(c cert.CertificateInfo) any { return joinLines(c.OCSPServers) }},
+	{name: "issuing_certificate_urls", header: "Issuing Certificate URLs", value: func(c cert.CertificateInfo) any { return joinLines(c.IssuingCertificateURLs) }},
+	{name: "crl_distribution_points", header: "CRL Distribution Points", value: func(c cert.CertificateInfo) any { return joinLines(c.CRLDistributionPoints) }},
+	{name: "is_ca", header: "CA", value: func(c cert.CertificateInfo) any { return c.IsCA }},
+
+	{name: "tls_version", header: "TLS Version", value: connectionValue(func(c *cert.ConnectionInfo) any { return c.TLSVersion })},
+	{name: "cipher_suite", header: "Cipher Suite", value: connectionValue(func(c *cert.ConnectionInfo) any { return c.CipherSuite })},
+	{name: "alpn", header: "ALPN", value: connectionValue(func(c *cert.ConnectionInfo) any { return c.ALPN })},
+	{name: "remote_addr", header: "Remote Address", value: connectionValue(func(c *cert.ConnectionInfo) any { return c.RemoteAddr })},
+	{name: "handshake_ms", header: "Handshake (ms)", value: connectionValue(func(c *cert.ConnectionInfo) any { return strconv.FormatFloat(c.HandshakeMillis, 'f', 2, 64) })},
+
+	{name: "hostname_match", header: "Hostname Match", value: func(c cert.CertificateInfo) any { return formatHostnameMatch(c) }, present: hasHostnameMismatch},
+	{name: "key_match", header: "Key Match", value: func(c cert.CertificateInfo) any { return formatKeyMatch(c.KeyMatch) }, present: hasKeyMatch},
+	{name: "findings", header: "Findings", value: func(c cert.CertificateInfo) any { return formatFindings(c.Findings) }, present: hasFindings},
+	{name: "scts", header: "SCTs", value: func(c cert.CertificateInfo) any { return formatCT(c) }, present: hasCT},
+	{name: "protocols", header: "Protocols", value: func(c cert.CertificateInfo) any { return formatProtocols(c.Protocols) }, present: hasProtocols},
+	{name: "ocsp", header: "OCSP", value: func(c cert.CertificateInfo) any { return formatOCSP(c.OCSP) }, present: hasOCSP},
+	{name: "crl", header: "CRL", value: func(c cert.CertificateInfo) any { return formatCRL(c.CRL) }, present: hasCRL},
+	{name: "client_auth", header: "Client Auth", value: func(c cert.CertificateInfo) any { return formatClientAuth(c.ClientAuth) }, present: hasClientAuth},
+	{name: "chain", header: "Chain", value: func(c cert.CertificateInfo) any { return formatChain(c) }, present: hasChain},
+}
+
+// FieldNames returns every field accepted by --fields, in table order
+func FieldNames() []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+
+	return names
+}
+
+// lookupColumn finds a column by its field name
+func lookupColumn(name string) (column, bool) {
+	for _, col := range columns {
+		if col.name == name {
+			return col, true
+		}
+	}
+
+	return column{}, false
+}
+
+// parseFields validates a --fields selection
+func parseFields(fields []string) ([]string, error) {
+	var selected []string
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if _, ok := lookupColumn(field); !ok {
+			return nil, fmt.Errorf("unknown field: %s (available: %s)", field, strings.Join(FieldNames(), ", "))
+		}
+		selected = append(selected, field)
+	}
+
+	return selected, nil
+}
+
+// tableColumns returns the columns to render: the selected fields, or the
+// defaults followed by the optional columns that have data
+func (f *Formatter) tableColumns(result *cert.Result) []column {
+	var selected []column
+
+	if len(f.fields) > 0 {
+		for _, name := range f.fields {
+			col, _ := lookupColumn(name)
+			selected = append(selected, col)
+		}
+		return selected
+	}
+
+	for _, name := range defaultFields {
+		col, _ := lookupColumn(name)
+		selected = append(selected, col)
+	}
+	for _, col := range columns {
+		if col.present != nil && col.present(result) {
+			selected = append(selected, col)
+		}
+	}
+
+	return selected
+}
+
+// connectionValue adapts a ConnectionInfo accessor to certificates without connection details
+func connectionValue(value func(*cert.ConnectionInfo) any) func(cert.CertificateInfo) any {
+	return func(c cert.CertificateInfo) any {
+		if c.Connection == nil {
+			return ""
+		}
+		return value(c.Connection)
+	}
+}
+
+// joinLines renders a list as a multi-line table cell
+func joinLines(values []string) string {
+	return strings.Join(values, "\n")
+}
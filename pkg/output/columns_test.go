@@ -0,0 +1,100 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_SetFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", fields: nil, want: nil},
+		{name: "normalized", fields: []string{" Host ", "SHA256_FINGERPRINT", ""}, want: []string{"host", "sha256_fingerprint"}},
+		{name: "unknown", fields: []string{"host", "nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := New()
+			err := formatter.SetFields(tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(formatter.fields, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SetFields() fields = %v, want %v", formatter.fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	names := FieldNames()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("FieldNames() has duplicate %q", name)
+		}
+		seen[name] = true
+	}
+
+	for _, name := range defaultFields {
+		if !seen[name] {
+			t.Errorf("default field %q is not a known column", name)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithFields(t *testing.T) {
+	formatter := New()
+	if err := formatter.SetFields([]string{"host", "sha256_fingerprint", "key_size", "tls_version"}); err != nil {
+		t.Fatalf("SetFields() unexpected error: %v", err)
+	}
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:              "example.com:443",
+				CommonName:        "example.com",
+				Issuer:            "Example CA",
+				SHA256Fingerprint: "AB:CD:EF",
+				KeySize:           2048,
+				Status:            cert.StatusOK,
+				Connection:        &cert.ConnectionInfo{TLSVersion: "TLS 1.3"},
+				OCSP:              &cert.OCSPInfo{Status: "good"},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"SHA-256 Fingerprint", "AB:CD:EF", "Key Size", "2048", "TLS Version", "TLS 1.3"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Example CA", "Common Name", "OCSP"} {
+		if strings.Contains(tableStr, unwanted) {
+			t.Errorf("Table output should not contain %q", unwanted)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package output
+
+import "github.com/guessi/ssl-certs-checker/pkg/cert"
+
+// column is a table column that can be selected with --fields
+type column struct {
+	// name is the field name accepted by --fields, matching the JSON key
+	name   string
+	header string
+	value  func(cert.CertificateInfo) any
+	// present marks an optional column: without --fields it is only shown
+	// when present reports data for at least one certificate
+	present func(*cert.Result) bool
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// FormatDiffTo formats certificate changes and writes to stdout or a file when outputFile is set
+func (f *Formatter) FormatDiffTo(diff *cert.Diff, format, outputFile string) error {
+	if diff == nil {
+		return fmt.Errorf("diff cannot be nil")
+	}
+
+	if outputFile != "" && strings.TrimSpace(outputFile) == "" {
+		return fmt.Errorf("output file path cannot be empty")
+	}
+
+	var output string
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %w", err)
+		}
+		output = string(data)
+	case "yaml":
+		data, err := yaml.Marshal(diff)
+		if err != nil {
+			return fmt.Errorf("error marshaling YAML: %w", err)
+		}
+		output = string(data)
+	case "table", "":
+		output = formatDiffTable(diff)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	return writeOutput(ensureTrailingNewline(output), outputFile)
+}
+
+// formatDiffTable renders one row per changed field, or per host when no field applies
+func formatDiffTable(diff *cert.Diff) string {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Host", "Change", "Field", "Old", "New"})
+
+	for _, change := range diff.Changes {
+		switch {
+		case len(change.Fields) > 0:
+			for _, field := range change.Fields {
+				t.AppendRow(table.Row{change.Host, change.Change, field.Field, field.Old, field.New})
+			}
+		case change.Error != "":
+			t.AppendRow(table.Row{change.Host, change.Change, "error", "", change.Error})
+		default:
+			t.AppendRow(table.Row{change.Host, change.Change, "", "", ""})
+		}
+	}
+
+	t.Style().Format.Header = text.FormatDefault
+
+	return t.Render()
+}
+
+// ReadResult loads a result written with --output json or --output yaml
+func ReadResult(path string) (*cert.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read result file: %w", err)
+	}
+
+	var result cert.Result
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, &result)
+	} else {
+		err = yaml.Unmarshal(data, &result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid result file %s: %w", path, err)
+	}
+
+	if result.Certificates == nil && result.Errors == nil {
+		return nil, fmt.Errorf("no certificates found in result file %s", path)
+	}
+
+	return &result, nil
+}
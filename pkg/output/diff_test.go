@@ -0,0 +1,107 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestReadResult(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:              "example.com:443",
+				CommonName:        "example.com",
+				DNSNames:          []string{"example.com"},
+				NotAfter:          time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Status:            cert.StatusOK,
+				SHA256Fingerprint: "AA:BB",
+			},
+		},
+		Errors: []cert.ErrorInfo{{Host: "invalid.com:443", Error: "connection failed"}},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "result."+format)
+			if err := formatter.FormatTo(result, format, path); err != nil {
+				t.Fatalf("FormatTo() unexpected error: %v", err)
+			}
+
+			got, err := ReadResult(path)
+			if err != nil {
+				t.Fatalf("ReadResult() unexpected error: %v", err)
+			}
+			if len(got.Certificates) != 1 || len(got.Errors) != 1 {
+				t.Fatalf("ReadResult() = %+v, want one certificate and one error", got)
+			}
+			if diff := cert.DiffResults(result, got); diff.HasChanges() {
+				t.Errorf("ReadResult() did not round-trip: %+v", diff.Changes)
+			}
+		})
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.yaml")
+	if err := os.WriteFile(empty, []byte("hosts: []\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := ReadResult(empty); err == nil {
+		t.Error("ReadResult() expected error for a file that is not a result")
+	}
+	if _, err := ReadResult(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("ReadResult() expected error for a missing file")
+	}
+}
+
+func TestFormatter_FormatDiffTo(t *testing.T) {
+	formatter := New()
+	diff := &cert.Diff{Changes: []cert.CertificateChange{
+		{
+			Host:   "renewed.example.com:443",
+			Change: cert.ChangeChanged,
+			Fields: []cert.FieldChange{{Field: "serial_number", Old: "01", New: "02"}},
+		},
+		{Host: "down.example.com:443", Change: cert.ChangeFailed, Error: "connection refused"},
+		{Host: "new.example.com:443", Change: cert.ChangeAdded},
+	}}
+
+	tablePath := filepath.Join(t.TempDir(), "diff.txt")
+	if err := formatter.FormatDiffTo(diff, "table", tablePath); err != nil {
+		t.Fatalf("FormatDiffTo() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(tablePath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	for _, want := range []string{"Change", "serial_number", "connection refused", "added"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("table output missing %q:\n%s", want, data)
+		}
+	}
+
+	jsonPath := filepath.Join(t.TempDir(), "diff.json")
+	if err := formatter.FormatDiffTo(diff, "json", jsonPath); err != nil {
+		t.Fatalf("FormatDiffTo() unexpected error: %v", err)
+	}
+	data, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	var got cert.Diff
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Output file should contain valid JSON: %v", err)
+	}
+	if len(got.Changes) != 3 || got.Changes[0].Fields[0].New != "02" {
+		t.Errorf("JSON output = %+v, want the three changes", got.Changes)
+	}
+
+	if err := formatter.FormatDiffTo(diff, "xml", ""); err == nil {
+		t.Error("FormatDiffTo() expected error for an unsupported format")
+	}
+}
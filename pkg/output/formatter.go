@@ -21,6 +21,17 @@ func New() *Formatter {
 	return &Formatter{}
 }
 
+// SetFields selects the table columns by field name; an empty selection restores the defaults
+func (f *Formatter) SetFields(fields []string) error {
+	selected, err := parseFields(fields)
+	if err != nil {
+		return err
+	}
+
+	f.fields = selected
+	return nil
+}
+
 // Format formats the certificate results according to the specified format
 func (f *Formatter) Format(result *cert.Result, format string) error {
 	return f.FormatTo(result, format, "")
@@ -41,16 +52,16 @@ func (f *Formatter) FormatTo(result *cert.Result, format, outputFile string) err
 		return err
 	}
 
-	if outputFile == "" {
-		fmt.Print(output)
-		return nil
-	}
+	return writeOutput(output, outputFile)
+}
 
-	if err := writeOutputFile(outputFile, []byte(output)); err != nil {
-		return fmt.Errorf("error writing output file: %w", err)
+// Render returns the certificate results in the specified format
+func (f *Formatter) Render(result *cert.Result, format string) (string, error) {
+	if result == nil {
+		return "", fmt.Errorf("result cannot be nil")
 	}
 
-	return nil
+	return f.render(result, format)
 }
 
 func (f *Formatter) render(result *cert.Result, format string) (string, error) {
@@ -88,32 +99,21 @@ func (f *Formatter) formatYAML(result *cert.Result) (string, error) {
 
 // formatTable outputs the results in table format
 func (f *Formatter) formatTable(result *cert.Result) (string, error) {
+	cols := f.tableColumns(result)
+
 	t := table.NewWriter()
-	t.AppendHeader(table.Row{
-		"Host",
-		"Common Name",
-		"DNS Names",
-		"Not Before",
-		"Not After",
-		"PublicKeyAlgorithm",
-		"Issuer",
-	})
+	header := make(table.Row, len(cols))
+	for i, col := range cols {
+		header[i] = col.header
+	}
+	t.AppendHeader(header)
 
 	for _, certInfo := range result.Certificates {
-		dnsNames := ""
-		if len(certInfo.DNSNames) > 0 {
-			dnsNames = strings.Join(certInfo.DNSNames, "\n")
+		row := make(table.Row, len(cols))
+		for i, col := range cols {
+			row[i] = col.value(certInfo)
 		}
-
-		t.AppendRows([]table.Row{{
-			certInfo.Host,
-			certInfo.CommonName,
-			dnsNames,
-			certInfo.NotBefore,
-			certInfo.NotAfter,
-			certInfo.PublicKeyAlgorithm,
-			certInfo.Issuer,
-		}})
+		t.AppendRows([]table.Row{row})
 	}
 
 	if len(result.Errors) > 0 {
@@ -130,6 +130,307 @@ func (f *Formatter) formatTable(result *cert.Result) (string, error) {
 	return output, nil
 }
 
+// formatVerified renders the verification outcome for a table cell
+func formatVerified(certInfo cert.CertificateInfo) string {
+	if certInfo.Verified {
+		return "yes"
+	}
+
+	if certInfo.VerificationFailure != "" {
+		return fmt.Sprintf("no (%s)", certInfo.VerificationFailure)
+	}
+
+	return "no"
+}
+
+// hasHostnameMismatch reports whether any certificate misses an expected name
+func hasHostnameMismatch(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if !certInfo.HostnameMatch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatHostnameMatch renders the hostname coverage for a table cell
+func formatHostnameMatch(certInfo cert.CertificateInfo) string {
+	if certInfo.HostnameMatch {
+		return "yes"
+	}
+
+	lines := []string{"no"}
+	for _, name := range certInfo.MissingNames {
+		lines = append(lines, "missing: "+name)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasKeyMatch reports whether any inspected certificate was matched against a private key
+func hasKeyMatch(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.KeyMatch != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatKeyMatch renders the private key match for a table cell
+func formatKeyMatch(info *cert.KeyMatchInfo) string {
+	if info == nil {
+		return ""
+	}
+	if !info.Matched {
+		return "no"
+	}
+
+	return "yes\n" + info.KeyFile
+}
+
+// hasFindings reports whether any certificate violates a rule
+func hasFindings(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if len(certInfo.Findings) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatFindings renders the findings for a table cell, one per line
+func formatFindings(findings []cert.Finding) string {
+	lines := make([]string, len(findings))
+	for i, finding := range findings {
+		lines[i] = fmt.Sprintf("[%s] %s: %s", finding.Severity, finding.ID, finding.Message)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasCT reports whether the CT policy was evaluated for any certificate
+func hasCT(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.CT != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatCT renders the CT policy outcome and the delivered SCTs for a table cell
+func formatCT(certInfo cert.CertificateInfo) string {
+	var lines []string
+	if policy := certInfo.CT; policy != nil {
+		verdict := "compliant"
+		if !policy.Compliant {
+			verdict = "not compliant"
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d of %d)", verdict, policy.Qualified, policy.Required))
+	}
+
+	for _, sct := range certInfo.SCTs {
+		log := sct.LogName
+		if log == "" {
+			log = sct.LogID
+		}
+		line := fmt.Sprintf("%s: %s", sct.Source, log)
+		if sct.Status != "" {
+			line += fmt.Sprintf(" (%s)", sct.Status)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasProtocols reports whether any certificate carries a protocol scan
+func hasProtocols(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.Protocols != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatProtocols summarizes a protocol scan for a table cell
+func formatProtocols(scan *cert.ProtocolScan) string {
+	if scan == nil {
+		return ""
+	}
+
+	lines := []string{"default: " + scan.NegotiatedVersion}
+	for _, version := range scan.Versions {
+		switch {
+		case !version.Supported:
+			lines = append(lines, version.Version+": no")
+		case version.Version == "TLS 1.3":
+			lines = append(lines, version.Version+": yes")
+		default:
+			insecure := 0
+			for _, cipher := range version.Ciphers {
+				if cipher.Insecure {
+					insecure++
+				}
+			}
+			noun := "ciphers"
+			if len(version.Ciphers) == 1 {
+				noun = "cipher"
+			}
+			line := fmt.Sprintf("%s: %d %s", version.Version, len(version.Ciphers), noun)
+			if insecure > 0 {
+				line += fmt.Sprintf(" (%d insecure)", insecure)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasOCSP reports whether any certificate carries OCSP details
+func hasOCSP(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.OCSP != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatOCSP renders the OCSP status for a table cell
+func formatOCSP(info *cert.OCSPInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	var lines []string
+	if info.Status != "" {
+		lines = append(lines, fmt.Sprintf("%s (%s)", info.Status, info.Source))
+	}
+	if !info.RevokedAt.IsZero() {
+		lines = append(lines, "revoked: "+info.RevokedAt.Format("2006-01-02"))
+	}
+	if info.Stapled {
+		lines = append(lines, "stapled")
+	} else {
+		lines = append(lines, "not stapled")
+	}
+	if info.Error != "" {
+		lines = append(lines, "! "+info.Error)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasCRL reports whether any certificate carries CRL details
+func hasCRL(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.CRL != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatCRL renders the CRL status for a table cell
+func formatCRL(info *cert.CRLInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	var lines []string
+	if info.Source != "" {
+		if info.Revoked {
+			lines = append(lines, "revoked: "+info.RevokedAt.Format("2006-01-02"))
+		} else {
+			lines = append(lines, "not revoked")
+		}
+		if !info.NextUpdate.IsZero() {
+			lines = append(lines, "next update: "+info.NextUpdate.Format("2006-01-02"))
+		}
+	}
+	if info.Error != "" {
+		lines = append(lines, "! "+info.Error)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasClientAuth reports whether any certificate carries client authentication details
+func hasClientAuth(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if certInfo.ClientAuth != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatClientAuth renders the server's client certificate request for a table cell
+func formatClientAuth(info *cert.ClientAuthInfo) string {
+	if info == nil || !info.Requested {
+		return "not requested"
+	}
+
+	lines := []string{"requested"}
+	if info.Sent {
+		lines[0] += ", sent"
+	} else {
+		lines[0] += ", none sent"
+	}
+	for _, ca := range info.AcceptableCAs {
+		lines = append(lines, "CA: "+ca)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hasChain reports whether any certificate carries chain details
+func hasChain(result *cert.Result) bool {
+	for _, certInfo := range result.Certificates {
+		if len(certInfo.Chain) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatChain renders the presented chain as a nested block for a table cell
+func formatChain(certInfo cert.CertificateInfo) string {
+	var lines []string
+	for i, element := range certInfo.Chain {
+		role := "leaf"
+		if element.IsCA {
+			role = "CA"
+		}
+
+		lines = append(lines,
+			fmt.Sprintf("[%d] %s (%s)", i, element.CommonName, role),
+			fmt.Sprintf("    issuer: %s", element.Issuer),
+			fmt.Sprintf("    serial: %s", element.SerialNumber),
+			fmt.Sprintf("    expires: %s (%s)", element.NotAfter.Format("2006-01-02"), element.Status),
+		)
+	}
+
+	for _, issue := range certInfo.ChainIssues {
+		lines = append(lines, "! "+issue)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func ensureTrailingNewline(content string) string {
 	if strings.HasSuffix(content, "\n") {
 		return content
@@ -138,6 +439,20 @@ func ensureTrailingNewline(content string) string {
 	return content + "\n"
 }
 
+// writeOutput prints output, or writes it to outputFile when set
+func writeOutput(output, outputFile string) error {
+	if outputFile == "" {
+		fmt.Print(output)
+		return nil
+	}
+
+	if err := writeOutputFile(outputFile, []byte(output)); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+
+	return nil
+}
+
 func writeOutputFile(path string, data []byte) error {
 	tmpDir := filepath.Dir(path)
 	tmpFile, err := os.CreateTemp(tmpDir, ".ssl-certs-checker-output-*")
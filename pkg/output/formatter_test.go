@@ -3,12 +3,16 @@ package output
 import (
 	"encoding/json"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"go.yaml.in/yaml/v3"
+
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
 )
 
@@ -33,6 +37,8 @@ func TestFormatter_Format_JSON(t *testing.T) {
 				NotAfter:           time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
 				PublicKeyAlgorithm: "RSA",
 				Issuer:             "Test CA",
+				DaysRemaining:      -30,
+				Status:             cert.StatusExpired,
 			},
 		},
 		Errors: []cert.ErrorInfo{
@@ -75,6 +81,10 @@ func TestFormatter_Format_JSON(t *testing.T) {
 	if len(jsonResult.Errors) != 1 {
 		t.Errorf("JSON output errors count = %d, want 1", len(jsonResult.Errors))
 	}
+
+	if got := jsonResult.Certificates[0].Status; got != cert.StatusExpired {
+		t.Errorf("JSON output status = %v, want %v", got, cert.StatusExpired)
+	}
 }
 
 func TestFormatter_Format_Table(t *testing.T) {
@@ -91,6 +101,8 @@ func TestFormatter_Format_Table(t *testing.T) {
 				NotAfter:           time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
 				PublicKeyAlgorithm: "RSA",
 				Issuer:             "Test CA",
+				DaysRemaining:      12,
+				Status:             cert.StatusWarning,
 			},
 		},
 		Errors: []cert.ErrorInfo{
@@ -138,6 +150,9 @@ func TestFormatter_Format_Table(t *testing.T) {
 	if !strings.Contains(tableStr, "RSA") {
 		t.Error("Table output should contain public key algorithm")
 	}
+	if !strings.Contains(tableStr, "WARNING") {
+		t.Error("Table output should contain certificate status")
+	}
 
 	// Verify errors are printed to stderr
 	errorStr := string(stderrOutput)
@@ -268,6 +283,53 @@ func TestFormatter_FormatTo_JSONFile(t *testing.T) {
 	}
 }
 
+func TestFormatter_Render_YAMLKeys(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:          "example.com:443",
+				CommonName:    "example.com",
+				DNSNames:      []string{"example.com"},
+				DaysRemaining: 30,
+				Status:        cert.StatusOK,
+				SerialNumber:  "01",
+				IsCA:          true,
+				Verified:      true,
+				OCSP:          &cert.OCSPInfo{Stapled: true, Status: cert.OCSPStatusGood, RevocationReason: 1},
+			},
+		},
+		Errors: []cert.ErrorInfo{{Host: "invalid.com:443", Error: "connection failed"}},
+	}
+
+	decode := func(format string, unmarshal func([]byte, any) error) map[string]any {
+		output, err := formatter.Render(result, format)
+		if err != nil {
+			t.Fatalf("Render(%s) unexpected error: %v", format, err)
+		}
+		var got struct {
+			Certificates []map[string]any `json:"certificates" yaml:"certificates"`
+		}
+		if err := unmarshal([]byte(output), &got); err != nil {
+			t.Fatalf("Render(%s) returned invalid output: %v", format, err)
+		}
+		return got.Certificates[0]
+	}
+	jsonFields := decode("json", json.Unmarshal)
+	yamlFields := decode("yaml", yaml.Unmarshal)
+
+	// YAML uses the same snake_case keys as JSON
+	keys := func(fields map[string]any) []string {
+		return slices.Sorted(maps.Keys(fields))
+	}
+	if got, want := keys(yamlFields), keys(jsonFields); !slices.Equal(got, want) {
+		t.Errorf("YAML keys = %v, want the JSON keys %v", got, want)
+	}
+	if got, want := keys(yamlFields["ocsp"].(map[string]any)), keys(jsonFields["ocsp"].(map[string]any)); !slices.Equal(got, want) {
+		t.Errorf("YAML ocsp keys = %v, want the JSON keys %v", got, want)
+	}
+}
+
 func TestFormatter_FormatTo_TableFile(t *testing.T) {
 	formatter := New()
 	result := &cert.Result{
@@ -383,3 +445,305 @@ func TestFormatter_FormatTo_PreservesExistingFilePermissions(t *testing.T) {
 		t.Errorf("Output file permissions = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
 	}
 }
+
+func TestFormatter_FormatTo_TableWithHostnameMatch(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:          "www.example.com:443",
+				CommonName:    "www.example.com",
+				Status:        cert.StatusOK,
+				HostnameMatch: true,
+			},
+			{
+				Host:          "shop.example.com:443",
+				CommonName:    "www.example.com",
+				Status:        cert.StatusError,
+				MissingNames:  []string{"shop.example.com", "example.com"},
+				HostnameMatch: false,
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"Hostname Match", "missing: shop.example.com", "missing: example.com"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithFindings(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:          "legacy.example.com:443",
+				CommonName:    "legacy.example.com",
+				Status:        cert.StatusCritical,
+				HostnameMatch: true,
+				Findings: []cert.Finding{
+					{ID: cert.RuleWeakRSAKey, Severity: cert.SeverityCritical, Message: "RSA key is 1024 bits, below the 2048-bit minimum"},
+					{ID: cert.RuleLongValidity, Severity: cert.SeverityWarning, Message: "validity period is 730 days, above the 398-day maximum"},
+				},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"Findings", "[critical] weak_rsa_key: RSA key is 1024 bits", "[warning] long_validity"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+	if strings.Contains(tableStr, "Hostname Match") {
+		t.Error("Table output should not contain a Hostname Match column when every name matches")
+	}
+}
+
+func TestFormatter_FormatTo_TableWithSCTs(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:          "ct.example.com:443",
+				CommonName:    "ct.example.com",
+				Status:        cert.StatusWarning,
+				HostnameMatch: true,
+				SCTs: []cert.SCTInfo{
+					{LogID: "AAAA", LogName: "Example Log", Source: cert.SCTSourceEmbedded, Status: cert.SCTStatusValid},
+					{LogID: "BBBB", Source: cert.SCTSourceTLS, Status: cert.SCTStatusUnknownLog},
+				},
+				CT: &cert.CTPolicy{Required: 2, Qualified: 1},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"SCTs", "not compliant (1 of 2)", "embedded: Example Log (valid)", "tls: BBBB (unknown_log)"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithProtocols(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "legacy.example.com:443",
+				CommonName: "legacy.example.com",
+				Status:     cert.StatusOK,
+				Protocols: &cert.ProtocolScan{
+					NegotiatedVersion: "TLS 1.2",
+					NegotiatedCipher:  "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+					Versions: []cert.VersionScan{
+						{Version: "TLS 1.3"},
+						{Version: "TLS 1.2", Supported: true, Ciphers: []cert.CipherScan{
+							{Name: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+							{Name: "TLS_RSA_WITH_RC4_128_SHA", Insecure: true},
+						}},
+						{Version: "TLS 1.0", Supported: true, Ciphers: []cert.CipherScan{
+							{Name: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"Protocols", "default: TLS 1.2", "TLS 1.3: no", "TLS 1.2: 2 ciphers (1 insecure)", "TLS 1.0: 1 cipher"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithOCSP(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "example.com:443",
+				CommonName: "example.com",
+				Status:     cert.StatusRevoked,
+				OCSP: &cert.OCSPInfo{
+					Stapled:   true,
+					Status:    cert.OCSPStatusRevoked,
+					Source:    cert.OCSPSourceStaple,
+					RevokedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"OCSP", "revoked (staple)", "revoked: 2025-03-01", "REVOKED"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithCRL(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "example.com:443",
+				CommonName: "example.com",
+				Status:     cert.StatusRevoked,
+				CRL: &cert.CRLInfo{
+					Source:     "http://crl.example.com/ca.crl",
+					Revoked:    true,
+					RevokedAt:  time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+					NextUpdate: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"CRL", "revoked: 2025-03-01", "next update: 2025-03-08", "REVOKED"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithClientAuth(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "mtls.example.com:443",
+				CommonName: "mtls.example.com",
+				Status:     cert.StatusOK,
+				ClientAuth: &cert.ClientAuthInfo{
+					Requested:     true,
+					AcceptableCAs: []string{"CN=Internal Client CA"},
+				},
+			},
+			{
+				Host:       "example.com:443",
+				CommonName: "example.com",
+				Status:     cert.StatusOK,
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"Client Auth", "requested, none sent", "CA: CN=Internal Client CA", "not requested"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
+
+func TestFormatter_FormatTo_TableWithChain(t *testing.T) {
+	formatter := New()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "example.com:443",
+				CommonName: "example.com",
+				Status:     cert.StatusOK,
+				Chain: []cert.CertificateInfo{
+					{CommonName: "example.com", Issuer: "Test Intermediate", SerialNumber: "0A:0B", Status: cert.StatusOK},
+					{CommonName: "Test Intermediate", Issuer: "Test Root", SerialNumber: "01", IsCA: true, Status: cert.StatusWarning},
+				},
+				ChainIssues: []string{"chain includes root certificate at position 2 (Test Root)"},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "result.txt")
+	if err := formatter.FormatTo(result, "table", outputPath); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	tableStr := string(data)
+	for _, want := range []string{"Chain", "[1] Test Intermediate (CA)", "issuer: Test Root", "! chain includes root certificate"} {
+		if !strings.Contains(tableStr, want) {
+			t.Errorf("Table output should contain %q", want)
+		}
+	}
+}
@@ -1,3 +1,6 @@
 package output
 
-type Formatter struct{}
+type Formatter struct {
+	// fields are the table columns selected with --fields; empty means the defaults
+	fields []string
+}